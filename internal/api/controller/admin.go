@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/middleware"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+	"github.com/st-matskevich/item-based-recommendations/internal/db"
+)
+
+const (
+	NO_COMMON_TAGS  = "NO_COMMON_TAGS"
+	BELOW_THRESHOLD = "BELOW_THRESHOLD"
+	ALREADY_LIKED   = "ALREADY_LIKED"
+	DELETED_TASK    = "DELETED_TASK"
+	DRAFT_TASK      = "DRAFT_TASK"
+	EXPIRED_TASK    = "EXPIRED_TASK"
+	CLOSED_TASK     = "CLOSED_TASK"
+	NO_TASK_TAGS    = "NO_TASK_TAGS"
+)
+
+type TagWeight struct {
+	TagID  utils.UID `json:"tag"`
+	Weight float32   `json:"weight"`
+}
+
+type TagContribution struct {
+	TagID        utils.UID `json:"tag"`
+	UserWeight   float32   `json:"userWeight"`
+	TaskWeight   float32   `json:"taskWeight"`
+	Contribution float32   `json:"contribution"`
+}
+
+type CandidateDebug struct {
+	TaskID        utils.UID         `json:"task"`
+	Vector        []TagWeight       `json:"vector"`
+	Contributions []TagContribution `json:"contributions"`
+	Score         float32           `json:"score"`
+	Recommended   bool              `json:"recommended"`
+	DropReason    string            `json:"dropReason,omitempty"`
+}
+
+type RecommendationsDebug struct {
+	UserID     utils.UID        `json:"user"`
+	Vector     []TagWeight      `json:"vector"`
+	Threshold  float32          `json:"threshold"`
+	Candidates []CandidateDebug `json:"candidates"`
+	Excluded   []CandidateDebug `json:"excluded"`
+	Tags       []repository.Tag `json:"tags"`
+}
+
+type AdminController struct {
+	ProfileRepo repository.ProfileRepository
+	TasksRepo   repository.TasksRepository
+	TagsRepo    repository.TagsRepository
+	SQLClient   *db.SQLClient
+}
+
+func (controller *AdminController) GetRoutes() []utils.Route {
+	return []utils.Route{
+		{
+			Name:    "Debug User Recommendations",
+			Method:  "GET",
+			Pattern: "/admin/users/{user}/recommendations/debug",
+			Handler: middleware.AuthMiddleware(middleware.AdminMiddleware(controller.ProfileRepo, controller.HandleDebugRecommendations)),
+		},
+		{
+			Name:    "Import Tasks",
+			Method:  "POST",
+			Pattern: "/admin/tasks/import",
+			Handler: middleware.AuthMiddleware(middleware.AdminMiddleware(controller.ProfileRepo, controller.HandleImportTasks)),
+		},
+		{
+			Name:    "Export Tasks",
+			Method:  "GET",
+			Pattern: "/admin/tasks/export",
+			Handler: middleware.AuthMiddleware(middleware.AdminMiddleware(controller.ProfileRepo, controller.HandleExportTasks)),
+		},
+	}
+}
+
+func vectorToTagWeights(vector map[utils.UID]float32) []TagWeight {
+	result := []TagWeight{}
+	for tagID, weight := range vector {
+		result = append(result, TagWeight{tagID, weight})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Weight != result[j].Weight {
+			return result[i].Weight > result[j].Weight
+		}
+		return result[i].TagID > result[j].TagID
+	})
+
+	return result
+}
+
+func debugCandidate(userVector map[utils.UID]float32, taskID utils.UID, taskVector map[utils.UID]float32, threshold float32) CandidateDebug {
+	result := CandidateDebug{
+		TaskID:        taskID,
+		Vector:        vectorToTagWeights(taskVector),
+		Contributions: []TagContribution{},
+	}
+
+	for tagID, tagWeight := range taskVector {
+		if val, ok := userVector[tagID]; ok {
+			result.Contributions = append(result.Contributions, TagContribution{tagID, val, tagWeight, val * tagWeight})
+		}
+	}
+
+	sort.Slice(result.Contributions, func(i, j int) bool {
+		return result.Contributions[i].Contribution > result.Contributions[j].Contribution
+	})
+
+	result.Score = getTaskSimilarity(userVector, taskVector)
+	result.Recommended = result.Score >= threshold
+	if len(result.Contributions) == 0 && !result.Recommended {
+		result.DropReason = NO_COMMON_TAGS
+	} else if !result.Recommended {
+		result.DropReason = BELOW_THRESHOLD
+	}
+
+	return result
+}
+
+// excluded tasks aren't scored, so only the first reason is reported
+func excludedCandidate(task repository.ExcludedTask) CandidateDebug {
+	result := CandidateDebug{
+		TaskID:        task.TaskID,
+		Vector:        []TagWeight{},
+		Contributions: []TagContribution{},
+	}
+
+	switch {
+	case task.Deleted:
+		result.DropReason = DELETED_TASK
+	case task.State == repository.TASK_DRAFT:
+		result.DropReason = DRAFT_TASK
+	case task.State == repository.TASK_EXPIRED:
+		result.DropReason = EXPIRED_TASK
+	case task.State != repository.TASK_OPEN:
+		result.DropReason = CLOSED_TASK
+	case task.Liked:
+		result.DropReason = ALREADY_LIKED
+	case !task.Tagged:
+		result.DropReason = NO_TASK_TAGS
+	}
+
+	return result
+}
+
+func (controller *AdminController) HandleDebugRecommendations(r *http.Request) utils.HandlerResponse {
+	userID, err := utils.UIDFromString(mux.Vars(r)["user"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	limit := 50
+	if value := r.FormValue("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil {
+			return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+		}
+	}
+
+	if limit < 1 || limit > 500 {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+	}
+
+	threshold, err := getSimilarityThreshold()
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	now := time.Now()
+	userTags, err := controller.ProfileRepo.GetLikedTags(userID, now)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	tasksTags, err := controller.TasksRepo.GetTasksTags(userID, now)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	userVector := buildUserRecommendationsVector(userTags)
+	tasksVector := buildTasksRecommendationsVector(tasksTags)
+
+	result := RecommendationsDebug{
+		UserID:     userID,
+		Vector:     vectorToTagWeights(userVector),
+		Threshold:  threshold,
+		Candidates: []CandidateDebug{},
+	}
+
+	for taskID, taskVector := range tasksVector {
+		result.Candidates = append(result.Candidates, debugCandidate(userVector, taskID, taskVector, threshold))
+	}
+
+	sort.Slice(result.Candidates, func(i, j int) bool {
+		if result.Candidates[i].Score != result.Candidates[j].Score {
+			return result.Candidates[i].Score > result.Candidates[j].Score
+		}
+		return result.Candidates[i].TaskID > result.Candidates[j].TaskID
+	})
+
+	if len(result.Candidates) > limit {
+		result.Candidates = result.Candidates[:limit]
+	}
+
+	excluded, err := controller.TasksRepo.GetExcludedTasks(userID, limit)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	result.Excluded = []CandidateDebug{}
+	for _, task := range excluded {
+		result.Excluded = append(result.Excluded, excludedCandidate(task))
+	}
+
+	tagsID := []utils.UID{}
+	for tagID := range userVector {
+		tagsID = append(tagsID, tagID)
+	}
+	for _, candidate := range result.Candidates {
+		for _, tag := range candidate.Vector {
+			tagsID = append(tagsID, tag.TagID)
+		}
+	}
+
+	result.Tags, err = controller.TagsRepo.GetTags(tagsID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, result, nil)
+}
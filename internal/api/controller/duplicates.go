@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+type DuplicateTaskMessage struct {
+	utils.ErrorMessage
+	TaskID utils.UID `json:"taskId"`
+}
+
+// Jaccard index of two tags sets, tags are compared case-insensitively
+func getTagsOverlap(tags []string, other []string) float32 {
+	set := map[string]struct{}{}
+	for _, tag := range tags {
+		set[strings.ToLower(tag)] = struct{}{}
+	}
+
+	union := len(set)
+	intersection := 0
+	seen := map[string]struct{}{}
+	for _, tag := range other {
+		tag = strings.ToLower(tag)
+		if _, contains := seen[tag]; contains {
+			continue
+		}
+		seen[tag] = struct{}{}
+
+		if _, contains := set[tag]; contains {
+			intersection++
+		} else {
+			union++
+		}
+	}
+
+	if union == 0 {
+		return 0
+	}
+
+	return float32(intersection) / float32(union)
+}
+
+// check is on by default, DUPLICATE_TASK_THRESHOLD=0 disables it
+type duplicateConfig struct {
+	threshold     float64
+	tagsThreshold float64
+	windowDays    int
+	checkAll      bool
+}
+
+func getDuplicateConfig() (duplicateConfig, error) {
+	var err error
+	config := duplicateConfig{}
+
+	config.threshold, err = utils.GetEnvFloat("DUPLICATE_TASK_THRESHOLD", 0.6)
+	if err != nil {
+		return config, err
+	}
+
+	if config.threshold < 0 || config.threshold > 1 {
+		return config, utils.MakeConfigError("DUPLICATE_TASK_THRESHOLD")
+	}
+
+	config.tagsThreshold, err = utils.GetEnvFloat("DUPLICATE_TAGS_THRESHOLD", 0.5)
+	if err != nil {
+		return config, err
+	}
+
+	if config.tagsThreshold < 0 || config.tagsThreshold > 1 {
+		return config, utils.MakeConfigError("DUPLICATE_TAGS_THRESHOLD")
+	}
+
+	config.windowDays, err = utils.GetEnvInt("DUPLICATE_TASK_WINDOW_DAYS", 7)
+	if err != nil {
+		return config, err
+	}
+
+	if config.windowDays < 1 {
+		return config, utils.MakeConfigError("DUPLICATE_TASK_WINDOW_DAYS")
+	}
+
+	config.checkAll, err = utils.GetEnvBool("DUPLICATE_CHECK_ALL_TASKS", false)
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+// returns ID of an open task with similar text and overlapping tags or 0 if there is none,
+// candidates are the customer's recent tasks and, if DUPLICATE_CHECK_ALL_TASKS is set, all open tasks
+func (controller *TasksController) findDuplicateTask(input InputTask) (utils.UID, error) {
+	config, err := getDuplicateConfig()
+	if err != nil || config.threshold == 0 {
+		return 0, err
+	}
+
+	candidates, err := controller.TasksRepo.FindSimilarTasks(input.Customer.ID, input.Name+" "+input.Description, config.windowDays, config.checkAll, float32(config.threshold))
+	if err != nil {
+		return 0, err
+	}
+
+	tags := []string{}
+	for _, tag := range input.Tags {
+		tags = append(tags, tag.Text)
+	}
+
+	for _, candidate := range candidates {
+		if getTagsOverlap(tags, candidate.Tags) >= float32(config.tagsThreshold) {
+			return candidate.ID, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func makeDuplicateTaskMessage(taskID utils.UID) DuplicateTaskMessage {
+	return DuplicateTaskMessage{utils.MakeErrorMessage(utils.DUPLICATE_TASK), taskID}
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+func TestGetTagsOverlap(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		other   []string
+		overlap float32
+	}{
+		{"both empty", []string{}, []string{}, 0},
+		{"one empty", []string{"go"}, []string{}, 0},
+		{"same tags", []string{"go", "sql"}, []string{"sql", "go"}, 1},
+		{"disjoint tags", []string{"go"}, []string{"sql"}, 0},
+		{"partial overlap", []string{"go", "sql"}, []string{"go", "web"}, float32(1) / 3},
+		{"case insensitive", []string{"Go"}, []string{"gO"}, 1},
+		{"duplicate tags", []string{"go", "go"}, []string{"go", "GO", "sql"}, 0.5},
+		{"subset", []string{"go", "sql", "web", "api"}, []string{"go"}, 0.25},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			overlap := getTagsOverlap(test.tags, test.other)
+			if overlap != test.overlap {
+				t.Errorf("getTagsOverlap(%v, %v) = %v, want %v", test.tags, test.other, overlap, test.overlap)
+			}
+
+			// overlap is symmetric
+			reverse := getTagsOverlap(test.other, test.tags)
+			if reverse != overlap {
+				t.Errorf("getTagsOverlap(%v, %v) = %v, want %v", test.other, test.tags, reverse, overlap)
+			}
+		})
+	}
+}
+
+func TestGetDuplicateConfig(t *testing.T) {
+	t.Setenv("DUPLICATE_TASK_THRESHOLD", "")
+	t.Setenv("DUPLICATE_TAGS_THRESHOLD", "")
+	t.Setenv("DUPLICATE_TASK_WINDOW_DAYS", "")
+	t.Setenv("DUPLICATE_CHECK_ALL_TASKS", "")
+
+	config, err := getDuplicateConfig()
+	if err != nil {
+		t.Fatalf("getDuplicateConfig() with unset variables error = %v", err)
+	}
+
+	expected := duplicateConfig{threshold: 0.6, tagsThreshold: 0.5, windowDays: 7, checkAll: false}
+	if config != expected {
+		t.Errorf("getDuplicateConfig() with unset variables = %+v, want %+v", config, expected)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"DUPLICATE_TASK_THRESHOLD", "1.5"},
+		{"DUPLICATE_TASK_THRESHOLD", "high"},
+		{"DUPLICATE_TAGS_THRESHOLD", "-0.1"},
+		{"DUPLICATE_TASK_WINDOW_DAYS", "0"},
+		{"DUPLICATE_CHECK_ALL_TASKS", "sometimes"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+"="+test.value, func(t *testing.T) {
+			t.Setenv(test.name, test.value)
+
+			_, err := getDuplicateConfig()
+			var configErr *utils.ConfigError
+			if !errors.As(err, &configErr) || configErr.Name != test.name {
+				t.Errorf("getDuplicateConfig() error = %v, want config error of %s", err, test.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+// Marsaglia and Tsang method, shape should be >= 1
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	d := shape - 1.0/3.0
+	c := 1.0 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+func sampleBeta(rng *rand.Rand, alpha float64, beta float64) float64 {
+	x := sampleGamma(rng, alpha)
+	y := sampleGamma(rng, beta)
+	return x / (x + y)
+}
+
+// exploration is disabled if the rate is not set, rate of 1 would leave no room for recommendations
+func getExplorationRate() (float64, error) {
+	rate, err := utils.GetEnvFloat("EXPLORATION_RATE", 0)
+	if err != nil {
+		return 0, err
+	}
+
+	if rate < 0 || rate >= 1 {
+		return 0, utils.MakeConfigError("EXPLORATION_RATE")
+	}
+
+	return rate, nil
+}
+
+// slots are a part of the page limit, at least one slot is reserved if exploration is enabled
+func getExplorationSlotsCount(rate float64, limit int) int {
+	if rate <= 0 || limit < 1 {
+		return 0
+	}
+
+	return int(math.Ceil(rate * float64(limit)))
+}
+
+// picks tasks with tags that user haven't liked yet,
+// tag for each slot is chosen by Thompson sampling over previous exploration outcomes
+func getExplorationTasks(rng *rand.Rand, userVector map[utils.UID]float32, tasksTags []repository.TaskTagLink, excludedTasks []utils.UID, stats []repository.TagExplorationStats, slots int) map[utils.UID]utils.UID {
+	result := map[utils.UID]utils.UID{}
+
+	excluded := map[utils.UID]struct{}{}
+	for _, taskID := range excludedTasks {
+		excluded[taskID] = struct{}{}
+	}
+
+	candidates := map[utils.UID][]utils.UID{}
+	for _, row := range tasksTags {
+		if _, known := userVector[row.TagID]; known {
+			continue
+		}
+
+		if _, skip := excluded[row.TaskID]; skip {
+			continue
+		}
+
+		candidates[row.TagID] = append(candidates[row.TagID], row.TaskID)
+	}
+
+	tagsStats := map[utils.UID]repository.TagExplorationStats{}
+	for _, row := range stats {
+		tagsStats[row.TagID] = row
+	}
+
+	for len(result) < slots && len(candidates) > 0 {
+		bestTag := utils.UID(0)
+		bestSample := float64(-1)
+		for tagID := range candidates {
+			tagStats := tagsStats[tagID]
+			sample := sampleBeta(rng, float64(1+tagStats.Rewarded), float64(1+tagStats.Shown-tagStats.Rewarded))
+			if sample > bestSample {
+				bestTag = tagID
+				bestSample = sample
+			}
+		}
+
+		tasks := candidates[bestTag]
+		index := rng.Intn(len(tasks))
+		taskID := tasks[index]
+
+		tasks[index] = tasks[len(tasks)-1]
+		candidates[bestTag] = tasks[:len(tasks)-1]
+		if len(candidates[bestTag]) == 0 {
+			delete(candidates, bestTag)
+		}
+
+		if _, picked := result[taskID]; !picked {
+			result[taskID] = bestTag
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSampleGamma(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape float64
+	}{
+		{"unit shape", 1},
+		{"small shape", 2.5},
+		{"large shape", 30},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+
+			sum := float64(0)
+			count := 20000
+			for i := 0; i < count; i++ {
+				sample := sampleGamma(rng, test.shape)
+				if sample <= 0 {
+					t.Fatalf("sampleGamma(%v) = %v, want positive value", test.shape, sample)
+				}
+				sum += sample
+			}
+
+			// mean of Gamma(shape, 1) is shape
+			mean := sum / float64(count)
+			if math.Abs(mean-test.shape) > 0.05*test.shape {
+				t.Errorf("mean of sampleGamma(%v) = %v, want about %v", test.shape, mean, test.shape)
+			}
+		})
+	}
+}
+
+func TestSampleBeta(t *testing.T) {
+	tests := []struct {
+		name        string
+		alpha, beta float64
+	}{
+		{"uniform", 1, 1},
+		{"rewarded", 10, 2},
+		{"not rewarded", 1, 20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+
+			sum := float64(0)
+			count := 20000
+			for i := 0; i < count; i++ {
+				sample := sampleBeta(rng, test.alpha, test.beta)
+				if sample <= 0 || sample >= 1 {
+					t.Fatalf("sampleBeta(%v, %v) = %v, want value in (0, 1)", test.alpha, test.beta, sample)
+				}
+				sum += sample
+			}
+
+			expected := test.alpha / (test.alpha + test.beta)
+			mean := sum / float64(count)
+			if math.Abs(mean-expected) > 0.01 {
+				t.Errorf("mean of sampleBeta(%v, %v) = %v, want about %v", test.alpha, test.beta, mean, expected)
+			}
+		})
+	}
+}
+
+func TestSampleBetaIsDeterministic(t *testing.T) {
+	first := rand.New(rand.NewSource(42))
+	second := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 100; i++ {
+		a := sampleBeta(first, 3, 5)
+		b := sampleBeta(second, 3, 5)
+		if a != b {
+			t.Fatalf("sample %d differs for the same seed: %v != %v", i, a, b)
+		}
+	}
+}
+
+func TestGetExplorationRate(t *testing.T) {
+	tests := []struct {
+		value   string
+		rate    float64
+		invalid bool
+	}{
+		{"", 0, false},
+		{"0.2", 0.2, false},
+		{"1", 0, true},
+		{"-0.1", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Setenv("EXPLORATION_RATE", test.value)
+
+		rate, err := getExplorationRate()
+		if (err != nil) != test.invalid {
+			t.Errorf("getExplorationRate() with %q error = %v, want error %v", test.value, err, test.invalid)
+		}
+		if err == nil && rate != test.rate {
+			t.Errorf("getExplorationRate() with %q = %v, want %v", test.value, rate, test.rate)
+		}
+	}
+}
+
+func TestGetExplorationSlotsCount(t *testing.T) {
+	tests := []struct {
+		rate  float64
+		limit int
+		slots int
+	}{
+		{0, 20, 0},
+		{0.1, 20, 2},
+		{0.1, 25, 3},
+		{0.5, 10, 5},
+		{0.1, 1, 1},
+		{0.2, 0, 0},
+	}
+
+	for _, test := range tests {
+		slots := getExplorationSlotsCount(test.rate, test.limit)
+		if slots != test.slots {
+			t.Errorf("getExplorationSlotsCount(%v, %v) = %v, want %v", test.rate, test.limit, slots, test.slots)
+		}
+		if slots > test.limit {
+			t.Errorf("getExplorationSlotsCount(%v, %v) = %v exceeds the limit", test.rate, test.limit, slots)
+		}
+	}
+}
+
+func TestGetRecommendationsStartCursor(t *testing.T) {
+	cursor := getRecommendationsStartCursor([]ScoredTask{})
+	if !cursor.End {
+		t.Errorf("getRecommendationsStartCursor() without tasks = %+v, want end cursor", cursor)
+	}
+
+	tasks := []ScoredTask{{ID: 5, Score: 0.5}, {ID: 9, Score: 0.9}, {ID: 7, Score: 0.5}}
+	sorted := getTasksAfterCursor(tasks, nil)
+	after := getTasksAfterCursor(tasks, getRecommendationsStartCursor(sorted))
+	if len(after) != len(tasks) {
+		t.Errorf("tasks after the start cursor = %v, want all of %v", after, sorted)
+	}
+}
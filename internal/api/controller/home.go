@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+const (
+	RECOMMENDED_SOURCE = "RECOMMENDED"
+	FRESH_SOURCE       = "FRESH"
+	TRENDING_SOURCE    = "TRENDING"
+)
+
+type FeedSource struct {
+	Name   string
+	Weight int
+	Tasks  []repository.Task
+	Cursor *utils.Cursor
+	Next   *utils.Cursor
+}
+
+// smooth weighted round-robin over sources,
+// tasks already taken from another source are skipped
+func interleaveFeedSources(sources []FeedSource, limit int) []repository.Task {
+	result := []repository.Task{}
+	added := map[utils.UID]struct{}{}
+	current := make([]int, len(sources))
+
+	for len(result) < limit {
+		total := 0
+		selected := -1
+		for i, source := range sources {
+			if source.Weight <= 0 || len(source.Tasks) == 0 {
+				continue
+			}
+
+			current[i] += source.Weight
+			total += source.Weight
+			if selected == -1 || current[i] > current[selected] {
+				selected = i
+			}
+		}
+
+		if selected == -1 {
+			break
+		}
+		current[selected] -= total
+
+		task := sources[selected].Tasks[0]
+		sources[selected].Tasks = sources[selected].Tasks[1:]
+		sources[selected].Cursor = task.Cursor
+		if _, duplicate := added[task.ID]; duplicate {
+			continue
+		}
+
+		added[task.ID] = struct{}{}
+		task.Source = sources[selected].Name
+		result = append(result, task)
+	}
+
+	return result
+}
+
+var feedSourceDefaultWeights = map[string]int{
+	RECOMMENDED_SOURCE: 2,
+	FRESH_SOURCE:       1,
+	TRENDING_SOURCE:    1,
+}
+
+// weights are read from HOME_<SOURCE>_WEIGHT, zero weight turns the source off,
+// but at least one of the sources has to stay on
+func setFeedSourceWeights(sources []FeedSource) error {
+	total := 0
+	for i := range sources {
+		name := "HOME_" + sources[i].Name + "_WEIGHT"
+		weight, err := utils.GetEnvInt(name, feedSourceDefaultWeights[sources[i].Name])
+		if err != nil {
+			return err
+		}
+		if weight < 0 {
+			return utils.MakeConfigError(name)
+		}
+
+		sources[i].Weight = weight
+		total += weight
+	}
+
+	if total == 0 {
+		return utils.MakeConfigError("HOME_*_WEIGHT")
+	}
+	return nil
+}
+
+// home feed state shared by all of its sources
+type homeFeed struct {
+	userID      utils.UID
+	filters     repository.FeedFilters
+	at          time.Time
+	recommended *scoredRecommendations
+	trending    bool
+}
+
+// recommended tasks are excluded from the other sources and trending tasks from the fresh one,
+// so sources don't overlap and tasks don't repeat on the next pages
+func (controller *TasksController) getHomeFeedSource(feed *homeFeed, name string, cursor *utils.Cursor, limit int) ([]repository.Task, *utils.Cursor, error) {
+	exclude := []utils.UID{}
+	if feed.recommended != nil {
+		exclude = feed.recommended.tasksID()
+	}
+
+	switch name {
+	case RECOMMENDED_SOURCE:
+		return controller.getRecommendationsPage(feed.userID, feed.recommended, cursor, limit)
+	case TRENDING_SOURCE:
+		return controller.TasksRepo.GetTasksFeed(repository.FeedRequest{Scope: repository.TRENDING, Filters: feed.filters, Cursor: cursor, Limit: limit, At: feed.at, Exclude: exclude}, feed.userID)
+	}
+	return controller.TasksRepo.GetTasksFeed(repository.FeedRequest{Scope: repository.NOT_ASSIGNED_TASKS, Sort: repository.RECENTLY_PUBLISHED, Filters: feed.filters, Cursor: cursor, Limit: limit, At: feed.at, Exclude: exclude, ExcludeTrending: feed.trending}, feed.userID)
+}
+
+// home cursor keeps a position for every source,
+// missing source means that it wasn't read yet
+func (controller *TasksController) GetHomeFeed(userID utils.UID, filters repository.FeedFilters, cursor *utils.Cursor, limit int, at time.Time) ([]repository.Task, *utils.Cursor, error) {
+	sources := []FeedSource{
+		{Name: RECOMMENDED_SOURCE},
+		{Name: FRESH_SOURCE},
+		{Name: TRENDING_SOURCE},
+	}
+
+	err := setFeedSourceWeights(sources)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	feed := &homeFeed{userID: userID, filters: filters, at: at}
+	for i := range sources {
+		source := &sources[i]
+		if cursor != nil {
+			source.Cursor = cursor.Sources[source.Name]
+		}
+
+		if source.Weight <= 0 {
+			continue
+		}
+
+		switch source.Name {
+		case RECOMMENDED_SOURCE:
+			feed.recommended, err = controller.scoreRecommendations(userID, filters, at)
+			if err != nil {
+				return nil, nil, err
+			}
+		case TRENDING_SOURCE:
+			feed.trending = true
+		}
+	}
+
+	for i := range sources {
+		source := &sources[i]
+		if source.Weight <= 0 || (source.Cursor != nil && source.Cursor.End) {
+			continue
+		}
+
+		source.Tasks, source.Next, err = controller.getHomeFeedSource(feed, source.Name, source.Cursor, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// first page of recommendations is served once, so exploration isn't repeated if none of its tasks were taken
+		if source.Name == RECOMMENDED_SOURCE && source.Cursor == nil {
+			source.Cursor = getRecommendationsStartCursor(feed.recommended.tasks)
+		}
+	}
+
+	result := interleaveFeedSources(sources, limit)
+
+	more := false
+	next := &utils.Cursor{Sources: map[string]*utils.Cursor{}}
+	for _, source := range sources {
+		if len(source.Tasks) == 0 && source.Next == nil {
+			next.Sources[source.Name] = &utils.Cursor{End: true}
+			continue
+		}
+
+		more = true
+		if source.Cursor != nil {
+			next.Sources[source.Name] = source.Cursor
+		}
+	}
+
+	if !more {
+		return result, nil, nil
+	}
+
+	return result, next, nil
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+func makeSourceTasks(ids ...utils.UID) []repository.Task {
+	result := []repository.Task{}
+	for _, id := range ids {
+		task := repository.Task{}
+		task.ID = id
+		task.Cursor = &utils.Cursor{ID: id}
+		result = append(result, task)
+	}
+	return result
+}
+
+func TestInterleaveFeedSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []FeedSource
+		limit   int
+		ids     []utils.UID
+		origins []string
+	}{
+		{
+			name: "equal weights",
+			sources: []FeedSource{
+				{Name: "A", Weight: 1, Tasks: makeSourceTasks(1, 2)},
+				{Name: "B", Weight: 1, Tasks: makeSourceTasks(3, 4)},
+			},
+			limit:   4,
+			ids:     []utils.UID{1, 3, 2, 4},
+			origins: []string{"A", "B", "A", "B"},
+		},
+		{
+			name: "weighted",
+			sources: []FeedSource{
+				{Name: "A", Weight: 2, Tasks: makeSourceTasks(1, 2, 3)},
+				{Name: "B", Weight: 1, Tasks: makeSourceTasks(4, 5)},
+			},
+			limit:   3,
+			ids:     []utils.UID{1, 4, 2},
+			origins: []string{"A", "B", "A"},
+		},
+		{
+			name: "duplicates are skipped",
+			sources: []FeedSource{
+				{Name: "A", Weight: 1, Tasks: makeSourceTasks(1, 2)},
+				{Name: "B", Weight: 1, Tasks: makeSourceTasks(1, 3)},
+			},
+			limit:   3,
+			ids:     []utils.UID{1, 2, 3},
+			origins: []string{"A", "A", "B"},
+		},
+		{
+			name: "limit",
+			sources: []FeedSource{
+				{Name: "A", Weight: 1, Tasks: makeSourceTasks(1, 2, 3)},
+			},
+			limit:   2,
+			ids:     []utils.UID{1, 2},
+			origins: []string{"A", "A"},
+		},
+		{
+			name: "zero weight",
+			sources: []FeedSource{
+				{Name: "A", Weight: 0, Tasks: makeSourceTasks(1, 2)},
+				{Name: "B", Weight: 1, Tasks: makeSourceTasks(3)},
+			},
+			limit:   3,
+			ids:     []utils.UID{3},
+			origins: []string{"B"},
+		},
+		{
+			name: "exhausted source",
+			sources: []FeedSource{
+				{Name: "A", Weight: 1, Tasks: makeSourceTasks(1)},
+				{Name: "B", Weight: 1, Tasks: makeSourceTasks(2, 3, 4)},
+			},
+			limit:   4,
+			ids:     []utils.UID{1, 2, 3, 4},
+			origins: []string{"A", "B", "B", "B"},
+		},
+		{
+			name:    "no sources",
+			sources: []FeedSource{},
+			limit:   2,
+			ids:     []utils.UID{},
+			origins: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := interleaveFeedSources(test.sources, test.limit)
+
+			ids := []utils.UID{}
+			origins := []string{}
+			for _, task := range result {
+				ids = append(ids, task.ID)
+				origins = append(origins, task.Source)
+			}
+
+			if !reflect.DeepEqual(ids, test.ids) {
+				t.Errorf("interleaveFeedSources() ids = %v, want %v", ids, test.ids)
+			}
+			if !reflect.DeepEqual(origins, test.origins) {
+				t.Errorf("interleaveFeedSources() sources = %v, want %v", origins, test.origins)
+			}
+		})
+	}
+}
+
+func TestInterleaveFeedSourcesMovesCursors(t *testing.T) {
+	sources := []FeedSource{
+		{Name: "A", Weight: 1, Tasks: makeSourceTasks(1, 2, 3)},
+		{Name: "B", Weight: 1, Tasks: makeSourceTasks(1)},
+	}
+
+	interleaveFeedSources(sources, 2)
+
+	// the duplicate is consumed too, so it isn't returned again on the next page
+	if sources[0].Cursor == nil || sources[0].Cursor.ID != 2 {
+		t.Errorf("source A cursor = %v, want task 2", sources[0].Cursor)
+	}
+	if sources[1].Cursor == nil || sources[1].Cursor.ID != 1 {
+		t.Errorf("source B cursor = %v, want task 1", sources[1].Cursor)
+	}
+}
+
+func TestSetFeedSourceWeights(t *testing.T) {
+	tests := []struct {
+		name        string
+		recommended string
+		fresh       string
+		trending    string
+		weights     []int
+		invalid     bool
+	}{
+		{"defaults", "", "", "", []int{2, 1, 1}, false},
+		{"custom", "5", "0", "3", []int{5, 0, 3}, false},
+		{"single source", "0", "1", "0", []int{0, 1, 0}, false},
+		{"negative", "-1", "", "", nil, true},
+		{"all off", "0", "0", "0", nil, true},
+		{"not a number", "", "abc", "", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("HOME_RECOMMENDED_WEIGHT", test.recommended)
+			t.Setenv("HOME_FRESH_WEIGHT", test.fresh)
+			t.Setenv("HOME_TRENDING_WEIGHT", test.trending)
+
+			sources := []FeedSource{
+				{Name: RECOMMENDED_SOURCE},
+				{Name: FRESH_SOURCE},
+				{Name: TRENDING_SOURCE},
+			}
+			err := setFeedSourceWeights(sources)
+			if (err != nil) != test.invalid {
+				t.Fatalf("setFeedSourceWeights() error = %v, want error %v", err, test.invalid)
+			}
+			if err != nil {
+				return
+			}
+
+			weights := []int{}
+			for _, source := range sources {
+				weights = append(weights, source.Weight)
+			}
+			if !reflect.DeepEqual(weights, test.weights) {
+				t.Errorf("setFeedSourceWeights() = %v, want %v", weights, test.weights)
+			}
+		})
+	}
+}
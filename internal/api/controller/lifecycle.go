@@ -0,0 +1,269 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+// checks that user owns the task and moves it to the target state
+func (controller *TasksController) transitionTask(r *http.Request, to string) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if customerID != uid {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+	}
+
+	from, err := controller.TasksRepo.GetTaskState(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	changed, err := controller.TasksRepo.TransitionTask(taskID, uid, from, to)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if !changed {
+		return utils.MakeHandlerResponse(http.StatusConflict, utils.MakeErrorMessage(utils.INVALID_TRANSITION), errors.New(utils.FORBIDDEN_TRANSITION))
+	}
+
+	task, err := controller.TasksRepo.GetTask(uid, taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, task, nil).WithHeader("ETag", utils.MakeVersionETag(task.Version))
+}
+
+func (controller *TasksController) HandleAssignTask(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	doer := repository.UserData{}
+	err := json.NewDecoder(r.Body).Decode(&doer)
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	if doer.ID == 0 || doer.ID == uid {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+	}
+
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if customerID != uid {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+	}
+
+	assigned, err := controller.TasksRepo.AssignTask(taskID, uid, doer.ID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if !assigned {
+		// task state is checked after the attempt, so concurrent closing is reported correctly
+		state, err := controller.TasksRepo.GetTaskState(taskID)
+		if err != nil {
+			return utils.MakeInternalErrorResponse(err)
+		}
+
+		if state != repository.TASK_OPEN {
+			return utils.MakeHandlerResponse(http.StatusConflict, utils.MakeErrorMessage(utils.TASK_CLOSED), errors.New(utils.TASK_NOT_OPEN))
+		}
+
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_DOER))
+	}
+
+	task, err := controller.TasksRepo.GetTask(uid, taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, task, nil).WithHeader("ETag", utils.MakeVersionETag(task.Version))
+}
+
+func (controller *TasksController) HandleCompleteTask(r *http.Request) utils.HandlerResponse {
+	return controller.transitionTask(r, repository.TASK_COMPLETED)
+}
+
+func (controller *TasksController) HandleCancelTask(r *http.Request) utils.HandlerResponse {
+	return controller.transitionTask(r, repository.TASK_CANCELLED)
+}
+
+func (controller *TasksController) HandleReopenTask(r *http.Request) utils.HandlerResponse {
+	return controller.transitionTask(r, repository.TASK_OPEN)
+}
+
+func (controller *TasksController) HandlePublishTask(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if customerID != uid {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+	}
+
+	published, err := controller.TasksRepo.PublishTask(taskID, uid)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if !published {
+		return utils.MakeHandlerResponse(http.StatusConflict, utils.MakeErrorMessage(utils.INVALID_TRANSITION), errors.New(utils.FORBIDDEN_TRANSITION))
+	}
+
+	go func() {
+		err := controller.NotifyTaskPublished(taskID)
+		if err != nil {
+			log.Printf("New task notifications error: %v", err)
+		}
+	}()
+
+	task, err := controller.TasksRepo.GetTask(uid, taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, task, nil).WithHeader("ETag", utils.MakeVersionETag(task.Version))
+}
+
+// body is optional, deadline has to be provided if the previous one has passed
+type RenewRequest struct {
+	Deadline *time.Time `json:"deadline"`
+}
+
+func (controller *TasksController) HandleRenewTask(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	input := RenewRequest{}
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil && err != io.EOF {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	if input.Deadline != nil && !input.Deadline.After(time.Now()) {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+	}
+
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if customerID != uid {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+	}
+
+	renewed, err := controller.TasksRepo.RenewTask(taskID, uid, input.Deadline)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if !renewed {
+		state, err := controller.TasksRepo.GetTaskState(taskID)
+		if err != nil {
+			return utils.MakeInternalErrorResponse(err)
+		}
+
+		if state != repository.TASK_EXPIRED {
+			return utils.MakeHandlerResponse(http.StatusConflict, utils.MakeErrorMessage(utils.INVALID_TRANSITION), errors.New(utils.FORBIDDEN_TRANSITION))
+		}
+
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+	}
+
+	task, err := controller.TasksRepo.GetTask(uid, taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, task, nil).WithHeader("ETag", utils.MakeVersionETag(task.Version))
+}
+
+// called by the scheduler, notification errors are logged so they don't stop other tasks from being processed
+func (controller *TasksController) PublishScheduledTasks() error {
+	published, err := controller.TasksRepo.PublishScheduledTasks()
+	if err != nil {
+		return err
+	}
+
+	for _, taskID := range published {
+		err = controller.NotifyTaskPublished(taskID)
+		if err != nil {
+			log.Printf("New task notifications error: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// history is visible only to the customer and the doer of the task
+func (controller *TasksController) HandleGetTaskHistory(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if customerID != uid {
+		doerID, err := controller.TasksRepo.GetTaskDoer(taskID)
+		if err != nil {
+			return utils.MakeInternalErrorResponse(err)
+		}
+
+		if doerID != uid {
+			return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+		}
+	}
+
+	history, err := controller.TasksRepo.GetTaskHistory(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, history, nil)
+}
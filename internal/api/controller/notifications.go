@@ -35,7 +35,7 @@ func (controller *NotificationsController) HandleGetNotifications(r *http.Reques
 
 	notifications, err := controller.NotificationsRepo.GetNotifications(uid)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
 	return utils.MakeHandlerResponse(http.StatusOK, notifications, nil)
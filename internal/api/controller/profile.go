@@ -48,7 +48,7 @@ func (controller *ProfileController) HandleGetUserProfile(r *http.Request) utils
 
 	profile, err := controller.ProfileRepo.GetProfile(uid)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
 	return utils.MakeHandlerResponse(http.StatusOK, profile, nil)
@@ -70,7 +70,7 @@ func (controller *ProfileController) HandleSetUserProfile(r *http.Request) utils
 
 	err = controller.ProfileRepo.SetProfile(uid, input)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
 	return utils.MakeHandlerResponse(http.StatusOK, struct{}{}, nil)
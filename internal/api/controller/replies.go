@@ -9,6 +9,7 @@ import (
 	"github.com/st-matskevich/item-based-recommendations/internal/api/middleware"
 	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
 	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+	"github.com/st-matskevich/item-based-recommendations/internal/db"
 )
 
 type TaskReplies struct {
@@ -18,9 +19,12 @@ type TaskReplies struct {
 }
 
 type RepliesController struct {
+	SQLClient         *db.SQLClient
 	RepliesRepo       repository.RepliesRepository
 	TasksRepo         repository.TasksRepository
 	NotificationsRepo repository.NotificationsRepository
+	ExplorationRepo   repository.ExplorationRepository
+	IdempotencyRepo   repository.IdempotencyRepository
 }
 
 func (controller *RepliesController) GetRoutes() []utils.Route {
@@ -35,7 +39,13 @@ func (controller *RepliesController) GetRoutes() []utils.Route {
 			Name:    "Create Reply",
 			Method:  "POST",
 			Pattern: "/tasks/{task}/replies",
-			Handler: middleware.AuthMiddleware(controller.HandleCreateReply),
+			Handler: middleware.AuthMiddleware(middleware.IdempotencyMiddleware(controller.IdempotencyRepo, controller.HandleCreateReply)),
+		},
+		{
+			Name:    "Get Reply",
+			Method:  "GET",
+			Pattern: "/tasks/{task}/replies/{reply}",
+			Handler: middleware.AuthMiddleware(controller.HandleGetReply),
 		},
 		{
 			Name:    "Hide Reply",
@@ -69,31 +79,67 @@ func (controller *RepliesController) HandleGetReplies(r *http.Request) utils.Han
 
 	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
 	result.User, err = controller.RepliesRepo.GetUserReply(taskID, uid)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
 	if customerID == uid {
 		result.Doer, err = controller.RepliesRepo.GetDoerReply(taskID)
 		if err != nil {
-			return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+			return utils.MakeInternalErrorResponse(err)
 		}
 	}
 
 	if customerID == uid {
 		result.All, err = controller.RepliesRepo.GetReplies(taskID)
 		if err != nil {
-			return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+			return utils.MakeInternalErrorResponse(err)
 		}
 	}
 
 	return utils.MakeHandlerResponse(http.StatusOK, result, nil)
 }
 
+// reply is visible to its creator and, unless it's hidden, to the customer of the task,
+// same as in the replies list
+func (controller *RepliesController) HandleGetReply(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	replyID, err := utils.UIDFromString(mux.Vars(r)["reply"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	reply, err := controller.RepliesRepo.GetReply(replyID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if reply.TaskID != taskID {
+		return utils.MakeHandlerResponse(http.StatusNotFound, utils.MakeErrorMessage(utils.NOT_FOUND), errors.New(utils.REPLY_NOT_FOUND))
+	}
+
+	if reply.Creator.ID != uid && (customerID != uid || reply.Hidden) {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, reply, nil)
+}
+
 func (controller *RepliesController) HandleCreateReply(r *http.Request) utils.HandlerResponse {
 	uid := utils.GetUserID(r.Context())
 
@@ -114,22 +160,44 @@ func (controller *RepliesController) HandleCreateReply(r *http.Request) utils.Ha
 		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
 	}
 
-	replyID, err := controller.RepliesRepo.CreateReply(taskID, input)
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
-	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	state, err := controller.TasksRepo.GetTaskState(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if state == repository.TASK_DRAFT {
+		return utils.MakeHandlerResponse(http.StatusConflict, utils.MakeErrorMessage(utils.TASK_CLOSED), errors.New(utils.TASK_NOT_OPEN))
+	}
+
+	replyID := utils.UID(0)
+	err = controller.SQLClient.WithTx(r.Context(), func(tx *db.SQLClient) error {
+		replyID, err = controller.RepliesRepo.WithTx(tx).CreateReply(taskID, input)
+		if err != nil {
+			return err
+		}
+
+		err = controller.NotificationsRepo.WithTx(tx).CreateNotification(customerID, repository.NEW_REPLY_NOTIFICATION, replyID)
+		if err != nil {
+			return err
+		}
+
+		return controller.ExplorationRepo.WithTx(tx).RewardExplorationSlot(uid, taskID)
+	})
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
-	err = controller.NotificationsRepo.CreateNotification(customerID, repository.NEW_REPLY_NOTIFICATION, replyID)
+	reply, err := controller.RepliesRepo.GetReply(replyID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
-	return utils.MakeHandlerResponse(http.StatusOK, struct{}{}, nil)
+	return utils.MakeCreatedResponse("/tasks/"+taskID.String()+"/replies/"+replyID.String(), reply)
 }
 
 func (controller *RepliesController) HandleHideReply(r *http.Request) utils.HandlerResponse {
@@ -147,7 +215,7 @@ func (controller *RepliesController) HandleHideReply(r *http.Request) utils.Hand
 
 	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
 	if customerID != uid {
@@ -156,7 +224,7 @@ func (controller *RepliesController) HandleHideReply(r *http.Request) utils.Hand
 
 	err = controller.RepliesRepo.HideReply(replyID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
 	return utils.MakeHandlerResponse(http.StatusOK, struct{}{}, nil)
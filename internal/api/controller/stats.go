@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+func (controller *TasksController) HandleGetTaskStats(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	days := 30
+	if value := r.FormValue("days"); value != "" {
+		days, err = strconv.Atoi(value)
+		if err != nil {
+			return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+		}
+	}
+
+	if days < 1 || days > 90 {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+	}
+
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if customerID != uid {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+	}
+
+	stats, err := controller.StatsRepo.GetTaskStats(taskID, days)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, stats, nil)
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/middleware"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+type Subscriptions struct {
+	Tags     []repository.Tag         `json:"tags"`
+	Searches []repository.SavedSearch `json:"searches"`
+}
+
+type InputSavedSearch struct {
+	Tags []utils.UID `json:"tags"`
+	repository.SavedSearch
+}
+
+type SubscriptionsController struct {
+	SubscriptionsRepo repository.SubscriptionsRepository
+}
+
+func (controller *SubscriptionsController) GetRoutes() []utils.Route {
+	return []utils.Route{
+		{
+			Name:    "Get Subscriptions",
+			Method:  "GET",
+			Pattern: "/subscriptions",
+			Handler: middleware.AuthMiddleware(controller.HandleGetSubscriptions),
+		},
+		{
+			Name:    "Subscribe To Tag",
+			Method:  "POST",
+			Pattern: "/subscriptions/tags/{tag}",
+			Handler: middleware.AuthMiddleware(controller.HandleSubscribeToTag),
+		},
+		{
+			Name:    "Unsubscribe From Tag",
+			Method:  "DELETE",
+			Pattern: "/subscriptions/tags/{tag}",
+			Handler: middleware.AuthMiddleware(controller.HandleUnsubscribeFromTag),
+		},
+		{
+			Name:    "Create Saved Search",
+			Method:  "POST",
+			Pattern: "/subscriptions/searches",
+			Handler: middleware.AuthMiddleware(controller.HandleCreateSavedSearch),
+		},
+		{
+			Name:    "Delete Saved Search",
+			Method:  "DELETE",
+			Pattern: "/subscriptions/searches/{search}",
+			Handler: middleware.AuthMiddleware(controller.HandleDeleteSavedSearch),
+		},
+	}
+}
+
+func validateSavedSearch(search InputSavedSearch) error {
+	if !repository.IsSavedSearchScope(search.Scope) {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if search.Query == "" && len(search.Tags) < 1 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if len([]rune(search.Query)) > 128 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if len(search.Tags) > 5 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	return nil
+}
+
+func (controller *SubscriptionsController) HandleGetSubscriptions(r *http.Request) utils.HandlerResponse {
+	var err error
+	uid := utils.GetUserID(r.Context())
+	result := Subscriptions{}
+
+	result.Tags, err = controller.SubscriptionsRepo.GetSubscribedTags(uid)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	result.Searches, err = controller.SubscriptionsRepo.GetSavedSearches(uid)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, result, nil)
+}
+
+func (controller *SubscriptionsController) HandleSubscribeToTag(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	tagID, err := utils.UIDFromString(mux.Vars(r)["tag"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	found, err := controller.SubscriptionsRepo.SubscribeToTag(uid, tagID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if !found {
+		return utils.MakeHandlerResponse(http.StatusNotFound, utils.MakeErrorMessage(utils.NOT_FOUND), errors.New(utils.TAG_NOT_FOUND))
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, struct{}{}, nil)
+}
+
+func (controller *SubscriptionsController) HandleUnsubscribeFromTag(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	tagID, err := utils.UIDFromString(mux.Vars(r)["tag"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	err = controller.SubscriptionsRepo.UnsubscribeFromTag(uid, tagID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, struct{}{}, nil)
+}
+
+func (controller *SubscriptionsController) HandleCreateSavedSearch(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	input := InputSavedSearch{}
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	if input.Scope == "" {
+		input.Scope = repository.NOT_ASSIGNED_TASKS
+	}
+
+	err = validateSavedSearch(input)
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), err)
+	}
+
+	searchID, err := controller.SubscriptionsRepo.CreateSavedSearch(uid, input.SavedSearch, input.Tags)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	search, err := controller.SubscriptionsRepo.GetSavedSearch(uid, searchID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeCreatedResponse("/subscriptions/searches/"+searchID.String(), search)
+}
+
+func (controller *SubscriptionsController) HandleDeleteSavedSearch(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	searchID, err := utils.UIDFromString(mux.Vars(r)["search"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	err = controller.SubscriptionsRepo.DeleteSavedSearch(uid, searchID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, struct{}{}, nil)
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+func TestValidateSavedSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   string
+		query   string
+		tags    []utils.UID
+		invalid bool
+	}{
+		{"query", repository.NOT_ASSIGNED_TASKS, "golang", nil, false},
+		{"tags", repository.NOT_ASSIGNED_TASKS, "", []utils.UID{1, 2}, false},
+		{"trending", repository.TRENDING, "logo design", nil, false},
+		{"own tasks scope", repository.CUSTOMER_TASKS, "golang", nil, true},
+		{"subscribed scope", repository.SUBSCRIBED, "golang", nil, true},
+		{"empty search", repository.NOT_ASSIGNED_TASKS, "", nil, true},
+		{"long query", repository.NOT_ASSIGNED_TASKS, strings.Repeat("a", 129), nil, true},
+		{"too many tags", repository.NOT_ASSIGNED_TASKS, "", []utils.UID{1, 2, 3, 4, 5, 6}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			search := InputSavedSearch{Tags: test.tags}
+			search.Scope = test.scope
+			search.Query = test.query
+
+			err := validateSavedSearch(search)
+			if (err != nil) != test.invalid {
+				t.Errorf("validateSavedSearch() error = %v, want error %v", err, test.invalid)
+			}
+		})
+	}
+}
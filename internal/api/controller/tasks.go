@@ -1,31 +1,94 @@
 package controller
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"log"
 	"math"
+	"math/rand"
 	"net/http"
-	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/st-matskevich/item-based-recommendations/internal/api/middleware"
 	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
 	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+	"github.com/st-matskevich/item-based-recommendations/internal/db"
 )
 
+// task is created as a draft if Draft or PublishAt is set
 type InputTask struct {
-	Tags []repository.Tag `json:"tags"`
+	Tags  []repository.Tag `json:"tags"`
+	Draft bool             `json:"draft"`
 	repository.Task
 }
 
+type TasksPage struct {
+	Tasks      []repository.Task `json:"tasks"`
+	NextCursor string            `json:"nextCursor"`
+}
+
+type LikesPage struct {
+	Likes      []repository.TaskLike `json:"likes"`
+	NextCursor string                `json:"nextCursor"`
+}
+
+type ScoredTask struct {
+	ID    utils.UID
+	Score float32
+}
+
 type TasksController struct {
+	SQLClient         *db.SQLClient
 	TasksRepo         repository.TasksRepository
 	ProfileRepo       repository.ProfileRepository
 	TagsRepo          repository.TagsRepository
 	RepliesRepo       repository.RepliesRepository
 	NotificationsRepo repository.NotificationsRepository
+	SubscriptionsRepo repository.SubscriptionsRepository
+	ExplorationRepo   repository.ExplorationRepository
+	IdempotencyRepo   repository.IdempotencyRepository
+	StatsRepo         repository.StatsRepository
+}
+
+// shared by the router and the scheduler, so both use the same repositories
+func NewTasksController(sqlClient *db.SQLClient) *TasksController {
+	return &TasksController{
+		SQLClient: sqlClient,
+		TasksRepo: &repository.TasksSQLRepository{
+			SQLClient: sqlClient,
+		},
+		ProfileRepo: &repository.ProfileSQLRepository{
+			SQLClient: sqlClient,
+		},
+		RepliesRepo: &repository.RepliesSQLRepository{
+			SQLClient: sqlClient,
+		},
+		NotificationsRepo: &repository.NotificationsSQLRepository{
+			SQLClient: sqlClient,
+		},
+		TagsRepo: &repository.TagsSQLRepository{
+			SQLClient: sqlClient,
+		},
+		SubscriptionsRepo: &repository.SubscriptionsSQLRepository{
+			SQLClient: sqlClient,
+		},
+		ExplorationRepo: &repository.ExplorationSQLRepository{
+			SQLClient: sqlClient,
+		},
+		IdempotencyRepo: &repository.IdempotencySQLRepository{
+			SQLClient: sqlClient,
+		},
+		StatsRepo: &repository.StatsSQLRepository{
+			SQLClient: sqlClient,
+		},
+	}
 }
 
 func (controller *TasksController) GetRoutes() []utils.Route {
@@ -48,41 +111,344 @@ func (controller *TasksController) GetRoutes() []utils.Route {
 			Pattern: "/tasks/{task}/like",
 			Handler: middleware.AuthMiddleware(controller.LikeTask),
 		},
+		{
+			Name:    "Get Task Likes",
+			Method:  "GET",
+			Pattern: "/tasks/{task}/likes",
+			Handler: middleware.AuthMiddleware(controller.HandleGetTaskLikes),
+		},
 		{
 			Name:    "Create Task",
 			Method:  "POST",
 			Pattern: "/tasks",
-			Handler: middleware.AuthMiddleware(controller.HandleCreateTask),
+			Handler: middleware.AuthMiddleware(middleware.IdempotencyMiddleware(controller.IdempotencyRepo, controller.HandleCreateTask)),
+		},
+		{
+			Name:    "Update Task",
+			Method:  "PUT",
+			Pattern: "/tasks/{task}",
+			Handler: middleware.AuthMiddleware(controller.HandleUpdateTask),
+		},
+		{
+			Name:    "Delete Task",
+			Method:  "DELETE",
+			Pattern: "/tasks/{task}",
+			Handler: middleware.AuthMiddleware(controller.HandleDeleteTask),
 		},
 		{
 			Name:    "Close Task",
 			Method:  "POST",
 			Pattern: "/tasks/{task}/close",
-			Handler: middleware.AuthMiddleware(controller.HandleCloseTask),
+			Handler: middleware.AuthMiddleware(controller.HandleAssignTask),
+		},
+		{
+			Name:    "Assign Task",
+			Method:  "POST",
+			Pattern: "/tasks/{task}/assign",
+			Handler: middleware.AuthMiddleware(controller.HandleAssignTask),
+		},
+		{
+			Name:    "Complete Task",
+			Method:  "POST",
+			Pattern: "/tasks/{task}/complete",
+			Handler: middleware.AuthMiddleware(controller.HandleCompleteTask),
+		},
+		{
+			Name:    "Cancel Task",
+			Method:  "POST",
+			Pattern: "/tasks/{task}/cancel",
+			Handler: middleware.AuthMiddleware(controller.HandleCancelTask),
+		},
+		{
+			Name:    "Publish Task",
+			Method:  "POST",
+			Pattern: "/tasks/{task}/publish",
+			Handler: middleware.AuthMiddleware(controller.HandlePublishTask),
+		},
+		{
+			Name:    "Renew Task",
+			Method:  "POST",
+			Pattern: "/tasks/{task}/renew",
+			Handler: middleware.AuthMiddleware(controller.HandleRenewTask),
+		},
+		{
+			Name:    "Reopen Task",
+			Method:  "POST",
+			Pattern: "/tasks/{task}/reopen",
+			Handler: middleware.AuthMiddleware(controller.HandleReopenTask),
+		},
+		{
+			Name:    "Get Task Stats",
+			Method:  "GET",
+			Pattern: "/tasks/{task}/stats",
+			Handler: middleware.AuthMiddleware(controller.HandleGetTaskStats),
+		},
+		{
+			Name:    "Get Task History",
+			Method:  "GET",
+			Pattern: "/tasks/{task}/history",
+			Handler: middleware.AuthMiddleware(controller.HandleGetTaskHistory),
 		},
 	}
 }
 
-func (controller *TasksController) HandleGetTasksFeed(r *http.Request) utils.HandlerResponse {
+func parseFeedRequest(r *http.Request) (repository.FeedRequest, error) {
 	var err error
-	var tasks []repository.Task
+	request := repository.FeedRequest{
+		Scope: r.FormValue("scope"),
+		Query: r.FormValue("query"),
+		Sort:  r.FormValue("sort"),
+		Limit: 20,
+	}
+
+	request.At = time.Now()
+	if value := r.FormValue("cursor"); value != "" {
+		request.Cursor, err = utils.CursorFromString(value)
+		if err != nil {
+			return request, err
+		}
+
+		if request.Cursor.At != nil {
+			request.At = *request.Cursor.At
+		}
+	}
+
+	if value := r.FormValue("limit"); value != "" {
+		request.Limit, err = strconv.Atoi(value)
+		if err != nil {
+			return request, err
+		}
+	}
+
+	if value := r.FormValue("highlight"); value != "" {
+		request.Highlight, err = strconv.ParseBool(value)
+		if err != nil {
+			return request, err
+		}
+	}
+
+	if value := r.FormValue("tags"); value != "" {
+		for _, tag := range strings.Split(value, ",") {
+			tagID, err := utils.UIDFromString(tag)
+			if err != nil {
+				return request, err
+			}
+			request.Filters.Tags = append(request.Filters.Tags, tagID)
+		}
+	}
+
+	request.Filters.TagsMode = r.FormValue("tagsMode")
+	request.Filters.State = r.FormValue("state")
+
+	if value := r.FormValue("createdAfter"); value != "" {
+		createdAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return request, err
+		}
+		request.Filters.CreatedAfter = &createdAfter
+	}
+
+	if value := r.FormValue("createdBefore"); value != "" {
+		createdBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return request, err
+		}
+		request.Filters.CreatedBefore = &createdBefore
+	}
+
+	request.Filters.Currency = r.FormValue("currency")
+
+	if value := r.FormValue("budgetMin"); value != "" {
+		budgetMin, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return request, err
+		}
+		value := int32(budgetMin)
+		request.Filters.BudgetMin = &value
+	}
 
+	if value := r.FormValue("budgetMax"); value != "" {
+		budgetMax, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return request, err
+		}
+		value := int32(budgetMax)
+		request.Filters.BudgetMax = &value
+	}
+
+	if value := r.FormValue("deadlineWithin"); value != "" {
+		request.Filters.DeadlineWithin, err = strconv.Atoi(value)
+		if err != nil {
+			return request, err
+		}
+	}
+
+	if r.FormValue("lat") != "" || r.FormValue("lon") != "" || r.FormValue("radius") != "" {
+		request.Filters.Near = &repository.Location{}
+		request.Filters.Near.Latitude, err = strconv.ParseFloat(r.FormValue("lat"), 64)
+		if err != nil {
+			return request, err
+		}
+
+		request.Filters.Near.Longitude, err = strconv.ParseFloat(r.FormValue("lon"), 64)
+		if err != nil {
+			return request, err
+		}
+
+		request.Filters.Radius, err = strconv.ParseFloat(r.FormValue("radius"), 64)
+		if err != nil {
+			return request, err
+		}
+	}
+
+	return request, nil
+}
+
+// cursors store a hash of the query, so they don't grow with it
+func feedQueryKey(query string) string {
+	if query == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(hash[:8])
+}
+
+// scores of these scopes depend on the request time, so their pages are computed at the time of the first one
+func isSnapshotScope(scope string) bool {
+	switch scope {
+	case repository.TRENDING, repository.RECOMMENDATIONS, repository.HOME:
+		return true
+	}
+	return false
+}
+
+// binds the next page cursor to the feed it was issued for, other feeds don't get the time,
+// so their cursors and etags stay the same while the content doesn't change
+func bindFeedCursor(cursor *utils.Cursor, request repository.FeedRequest) {
+	cursor.Scope = request.Scope
+	cursor.Sort = request.Sort
+	cursor.Query = feedQueryKey(request.Query)
+	if isSnapshotScope(request.Scope) {
+		cursor.At = &request.At
+	}
+}
+
+func validateFeedRequest(request repository.FeedRequest) error {
+	if request.Limit < 1 || request.Limit > 100 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	// cursor positions are meaningless in a feed with another order
+	if request.Cursor != nil {
+		if request.Cursor.Scope != request.Scope || request.Cursor.Sort != request.Sort || request.Cursor.Query != feedQueryKey(request.Query) {
+			return errors.New(utils.INVALID_INPUT)
+		}
+	}
+
+	if len([]rune(request.Query)) > 128 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if len(request.Filters.Tags) > 10 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if request.Sort != "" {
+		if !repository.IsFeedSort(request.Sort) {
+			return errors.New(utils.INVALID_INPUT)
+		}
+
+		if request.Scope == repository.RECOMMENDATIONS || request.Scope == repository.HOME {
+			return errors.New(utils.INVALID_INPUT)
+		}
+
+		if request.Sort == repository.RELEVANCE && request.Query == "" {
+			return errors.New(utils.INVALID_INPUT)
+		}
+	}
+
+	switch request.Filters.TagsMode {
+	case "", repository.ANY_TAGS, repository.ALL_TAGS:
+	default:
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	switch request.Filters.State {
+	case "", repository.OPEN_TASKS, repository.CLOSED_TASKS, repository.DRAFT_TASKS:
+	default:
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if request.Filters.CreatedAfter != nil && request.Filters.CreatedBefore != nil && !request.Filters.CreatedAfter.Before(*request.Filters.CreatedBefore) {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	// budgets in different currencies can't be compared
+	if (request.Filters.BudgetMin != nil || request.Filters.BudgetMax != nil) && request.Filters.Currency == "" {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if request.Filters.Currency != "" && !isCurrencyCode(request.Filters.Currency) {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if request.Filters.BudgetMin != nil && request.Filters.BudgetMax != nil && *request.Filters.BudgetMin > *request.Filters.BudgetMax {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if request.Filters.DeadlineWithin < 0 || request.Filters.DeadlineWithin > 365 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if request.Filters.Near != nil {
+		if !isValidLocation(*request.Filters.Near) {
+			return errors.New(utils.INVALID_INPUT)
+		}
+
+		if request.Filters.Radius <= 0 || request.Filters.Radius > 1000 {
+			return errors.New(utils.INVALID_INPUT)
+		}
+	}
+
+	return nil
+}
+
+func (controller *TasksController) HandleGetTasksFeed(r *http.Request) utils.HandlerResponse {
 	uid := utils.GetUserID(r.Context())
 
-	scope := r.FormValue("scope")
-	query := r.FormValue("query")
+	request, err := parseFeedRequest(r)
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
 
-	if scope == repository.RECOMMENDATIONS {
-		tasks, err = controller.GetRecommendations(uid)
-	} else {
-		tasks, err = controller.TasksRepo.GetTasksFeed(scope, query, uid)
+	err = validateFeedRequest(request)
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), err)
 	}
 
+	tasks, next, err := controller.GetTasksFeed(request, uid)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	result := TasksPage{Tasks: tasks}
+	if next != nil {
+		bindFeedCursor(next, request)
+		result.NextCursor = next.String()
 	}
 
-	return utils.MakeHandlerResponse(http.StatusOK, tasks, nil)
+	return utils.MakeHandlerResponse(http.StatusOK, result, nil)
+}
+
+func (controller *TasksController) GetTasksFeed(request repository.FeedRequest, userID utils.UID) ([]repository.Task, *utils.Cursor, error) {
+	switch request.Scope {
+	case repository.RECOMMENDATIONS:
+		return controller.GetRecommendations(userID, request.Filters, request.Cursor, request.Limit, request.At)
+	case repository.HOME:
+		return controller.GetHomeFeed(userID, request.Filters, request.Cursor, request.Limit, request.At)
+	}
+	return controller.TasksRepo.GetTasksFeed(request, userID)
 }
 
 func (controller *TasksController) HandleGetTask(r *http.Request) utils.HandlerResponse {
@@ -90,80 +456,372 @@ func (controller *TasksController) HandleGetTask(r *http.Request) utils.HandlerR
 
 	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	task, err := controller.TasksRepo.GetTask(uid, taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	// failed view tracking shouldn't prevent user from seeing the task
+	if !task.Owns {
+		err = controller.StatsRepo.AddTaskView(taskID, uid)
+		if err != nil {
+			log.Printf("Task view tracking error: %v", err)
+		}
+	}
+
+	// no Last-Modified, likes and replies counters change without updating the task
+	return utils.MakeHandlerResponse(http.StatusOK, task, nil).WithHeader("ETag", utils.MakeVersionETag(task.Version))
+}
+
+func (controller *TasksController) LikeTask(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	likes, err := strconv.ParseBool(r.FormValue("value"))
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	found, err := controller.TasksRepo.SetTaskLike(uid, taskID, likes)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if !found {
+		return utils.MakeHandlerResponse(http.StatusNotFound, utils.MakeErrorMessage(utils.NOT_FOUND), errors.New(utils.TASK_NOT_FOUND))
+	}
+
+	if likes {
+		err = controller.ExplorationRepo.RewardExplorationSlot(uid, taskID)
+		if err != nil {
+			return utils.MakeInternalErrorResponse(err)
+		}
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, likes, nil)
+}
+
+func (controller *TasksController) HandleGetTaskLikes(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	var cursor *utils.Cursor
+	if value := r.FormValue("cursor"); value != "" {
+		cursor, err = utils.CursorFromString(value)
+		if err != nil {
+			return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+		}
+	}
+
+	limit := 20
+	if value := r.FormValue("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil {
+			return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+		}
+	}
+
+	if limit < 1 || limit > 100 {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+	}
+
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if customerID != uid {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+	}
+
+	likes, next, err := controller.TasksRepo.GetTaskLikes(taskID, cursor, limit)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	result := LikesPage{Likes: likes}
+	if next != nil {
+		result.NextCursor = next.String()
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, result, nil)
+}
+
+// deadline has to be in the future only when it's set or changed, so overdue tasks can still be updated
+// with the deadline they already have, current is nil for new tasks
+func validateTaskDeadline(task InputTask, current *time.Time) error {
+	if task.Deadline == nil || (current != nil && task.Deadline.Equal(*current)) {
+		return nil
+	}
+
+	if !task.Deadline.After(time.Now()) {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	return nil
+}
+
+func validateTask(task InputTask) error {
+	if task.Name == "" || task.Description == "" {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if len([]rune(task.Name)) > 128 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if len([]rune(task.Description)) > 2048 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if len(task.Tags) > 5 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if len(task.Tags) < 1 {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	for _, tag := range task.Tags {
+		if tag.Text == "" {
+			return errors.New(utils.INVALID_INPUT)
+		}
+
+		if len([]rune(tag.Text)) > 32 {
+			return errors.New(utils.INVALID_INPUT)
+		}
+	}
+
+	if task.Budget != nil {
+		if task.Budget.Min < 0 || task.Budget.Min > task.Budget.Max {
+			return errors.New(utils.INVALID_INPUT)
+		}
+
+		if !isCurrencyCode(task.Budget.Currency) {
+			return errors.New(utils.INVALID_INPUT)
+		}
+	}
+
+	if task.PublishAt != nil && !task.PublishAt.After(time.Now()) {
+		return errors.New(utils.INVALID_INPUT)
+	}
+
+	if task.Location != nil {
+		if !isValidLocation(*task.Location) {
+			return errors.New(utils.INVALID_INPUT)
+		}
+
+		if len([]rune(task.Location.Place)) > 128 {
+			return errors.New(utils.INVALID_INPUT)
+		}
+	}
+
+	return nil
+}
+
+// expects ISO 4217 alphabetic code
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+
+	for _, letter := range code {
+		if letter < 'A' || letter > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isValidLocation(location repository.Location) bool {
+	return location.Latitude >= -90 && location.Latitude <= 90 && location.Longitude >= -180 && location.Longitude <= 180
+}
+
+func (controller *TasksController) HandleCreateTask(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
+	input := InputTask{}
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+	input.Customer.ID = uid
+	input.CreatedAt = time.Time{}
+
+	input.State = repository.TASK_OPEN
+	if input.Draft || input.PublishAt != nil {
+		input.State = repository.TASK_DRAFT
+	}
+
+	err = validateTask(input)
+	if err == nil {
+		err = validateTaskDeadline(input, nil)
+	}
+
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), err)
+	}
+
+	duplicateID, err := controller.findDuplicateTask(input)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	if duplicateID != 0 {
+		return utils.MakeHandlerResponse(http.StatusConflict, makeDuplicateTaskMessage(duplicateID), errors.New(utils.SIMILAR_TASK_EXISTS))
+	}
+
+	taskID, tagsID, err := createTask(r.Context(), controller.SQLClient, controller.TasksRepo, controller.TagsRepo, input)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	// drafts notify users once they are published
+	if input.State == repository.TASK_OPEN {
+		go func() {
+			err := controller.NotifyTaskCreated(taskID, uid, tagsID)
+			if err != nil {
+				log.Printf("New task notifications error: %v", err)
+			}
+		}()
 	}
 
 	task, err := controller.TasksRepo.GetTask(uid, taskID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
-	return utils.MakeHandlerResponse(http.StatusOK, task, nil)
+	return utils.MakeCreatedResponse("/tasks/"+taskID.String(), task).WithHeader("ETag", utils.MakeVersionETag(task.Version))
 }
 
-func (controller *TasksController) LikeTask(r *http.Request) utils.HandlerResponse {
-	uid := utils.GetUserID(r.Context())
+// creates the task and links its tags in a single transaction
+func createTask(ctx context.Context, sqlClient *db.SQLClient, tasksRepo repository.TasksRepository, tagsRepo repository.TagsRepository, input InputTask) (utils.UID, []utils.UID, error) {
+	taskID := utils.UID(0)
+	tagsID := []utils.UID{}
 
-	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
-	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
-	}
+	err := sqlClient.WithTx(ctx, func(tx *db.SQLClient) error {
+		var err error
+		txTagsRepo := tagsRepo.WithTx(tx)
 
-	likes, err := strconv.ParseBool(r.FormValue("value"))
-	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
-	}
+		taskID, err = tasksRepo.WithTx(tx).CreateTask(input.Task)
+		if err != nil {
+			return err
+		}
 
-	err = controller.TasksRepo.SetTaskLike(uid, taskID, likes)
-	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.SQL_ERROR), err)
-	}
+		tagsID, err = resolveTags(txTagsRepo, input.Tags)
+		if err != nil {
+			return err
+		}
 
-	return utils.MakeHandlerResponse(http.StatusOK, likes, nil)
+		for _, tagID := range tagsID {
+			err = txTagsRepo.AddTagToTask(taskID, tagID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return taskID, tagsID, err
 }
 
-func validateTask(task InputTask) error {
-	if task.Name == "" || task.Description == "" {
-		return errors.New(utils.INVALID_INPUT)
-	}
+func resolveTags(tagsRepo repository.TagsRepository, tags []repository.Tag) ([]utils.UID, error) {
+	var err error
+	result := []utils.UID{}
+	unique := map[utils.UID]struct{}{}
 
-	if len([]rune(task.Name)) > 128 {
-		return errors.New(utils.INVALID_INPUT)
+	for _, tag := range tags {
+		if tag.ID == 0 {
+			tag.Text = strings.ToLower(tag.Text)
+			tag.ID, err = tagsRepo.CreateTag(tag.Text)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if _, contains := unique[tag.ID]; !contains {
+			unique[tag.ID] = struct{}{}
+			result = append(result, tag.ID)
+		}
 	}
 
-	if len([]rune(task.Description)) > 2048 {
-		return errors.New(utils.INVALID_INPUT)
+	return result, nil
+}
+
+func updateTaskTags(tagsRepo repository.TagsRepository, taskID utils.UID, tagsID []utils.UID) error {
+	current, err := tagsRepo.GetTaskTags(taskID)
+	if err != nil {
+		return err
 	}
 
-	if len(task.Tags) > 5 {
-		return errors.New(utils.INVALID_INPUT)
+	updated := map[utils.UID]struct{}{}
+	for _, tagID := range tagsID {
+		updated[tagID] = struct{}{}
 	}
 
-	if len(task.Tags) < 1 {
-		return errors.New(utils.INVALID_INPUT)
+	for _, tag := range current {
+		if _, keep := updated[tag.ID]; keep {
+			delete(updated, tag.ID)
+			continue
+		}
+
+		err = tagsRepo.RemoveTagFromTask(taskID, tag.ID)
+		if err != nil {
+			return err
+		}
 	}
 
-	for _, tag := range task.Tags {
-		if tag.Text == "" {
-			return errors.New(utils.INVALID_INPUT)
+	for _, tagID := range tagsID {
+		if _, added := updated[tagID]; !added {
+			continue
 		}
 
-		if len([]rune(tag.Text)) > 32 {
-			return errors.New(utils.INVALID_INPUT)
+		err = tagsRepo.AddTagToTask(taskID, tagID)
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func (controller *TasksController) HandleCreateTask(r *http.Request) utils.HandlerResponse {
+func (controller *TasksController) HandleUpdateTask(r *http.Request) utils.HandlerResponse {
 	uid := utils.GetUserID(r.Context())
 
+	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return utils.MakeHandlerResponse(http.StatusPreconditionRequired, utils.MakeErrorMessage(utils.PRECONDITION_REQUIRED), errors.New(utils.INVALID_INPUT))
+	}
+
+	version, err := utils.ParseVersionETag(ifMatch)
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
 	input := InputTask{}
-	err := json.NewDecoder(r.Body).Decode(&input)
+	err = json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
 		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
 	}
+	input.ID = taskID
 	input.Customer.ID = uid
 
 	err = validateTask(input)
@@ -171,38 +829,60 @@ func (controller *TasksController) HandleCreateTask(r *http.Request) utils.Handl
 		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), err)
 	}
 
-	taskID, err := controller.TasksRepo.CreateTask(input.Task)
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
-	for _, tag := range input.Tags {
-		if tag.ID == 0 {
-			tag.Text = strings.ToLower(tag.Text)
-			tag.ID, err = controller.TagsRepo.CreateTag(tag.Text)
-			if err != nil {
-				return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
-			}
+	if customerID != uid {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+	}
+
+	current, err := controller.TasksRepo.GetTask(uid, taskID)
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
+
+	err = validateTaskDeadline(input, current.Deadline)
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), err)
+	}
+
+	updated := false
+	err = controller.SQLClient.WithTx(r.Context(), func(tx *db.SQLClient) error {
+		tagsRepo := controller.TagsRepo.WithTx(tx)
+
+		updated, err = controller.TasksRepo.WithTx(tx).UpdateTask(input.Task, version)
+		if err != nil || !updated {
+			return err
 		}
 
-		err = controller.TagsRepo.AddTagToTask(taskID, tag.ID)
+		tagsID, err := resolveTags(tagsRepo, input.Tags)
 		if err != nil {
-			return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+			return err
 		}
-	}
 
-	return utils.MakeHandlerResponse(http.StatusOK, struct{}{}, nil)
-}
+		return updateTaskTags(tagsRepo, taskID, tagsID)
+	})
+	if err != nil {
+		return utils.MakeInternalErrorResponse(err)
+	}
 
-func (controller *TasksController) HandleCloseTask(r *http.Request) utils.HandlerResponse {
-	uid := utils.GetUserID(r.Context())
+	if !updated {
+		return utils.MakeHandlerResponse(http.StatusPreconditionFailed, utils.MakeErrorMessage(utils.VERSION_CONFLICT), errors.New(utils.OUTDATED_VERSION))
+	}
 
-	doer := repository.UserData{}
-	err := json.NewDecoder(r.Body).Decode(&doer)
+	task, err := controller.TasksRepo.GetTask(uid, taskID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
+	return utils.MakeHandlerResponse(http.StatusOK, task, nil).WithHeader("ETag", utils.MakeVersionETag(task.Version))
+}
+
+func (controller *TasksController) HandleDeleteTask(r *http.Request) utils.HandlerResponse {
+	uid := utils.GetUserID(r.Context())
+
 	taskID, err := utils.UIDFromString(mux.Vars(r)["task"])
 	if err != nil {
 		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
@@ -210,21 +890,20 @@ func (controller *TasksController) HandleCloseTask(r *http.Request) utils.Handle
 
 	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
 	if customerID != uid {
 		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
 	}
 
-	err = controller.TasksRepo.CloseTask(taskID, doer.ID)
+	deleted, err := controller.TasksRepo.DeleteTask(taskID)
 	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		return utils.MakeInternalErrorResponse(err)
 	}
 
-	err = controller.NotificationsRepo.CreateNotification(doer.ID, repository.TASK_CLOSE_NOTIFICATION, taskID)
-	if err != nil {
-		return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+	if !deleted {
+		return utils.MakeHandlerResponse(http.StatusConflict, utils.MakeErrorMessage(utils.TASK_HAS_DOER), errors.New(utils.DOER_ALREADY_SELECTED))
 	}
 
 	return utils.MakeHandlerResponse(http.StatusOK, struct{}{}, nil)
@@ -286,38 +965,98 @@ func buildTasksRecommendationsVector(tasksTags []repository.TaskTagLink) map[uti
 	return result
 }
 
-func getRecommendedTasks(userVector map[utils.UID]float32, tasksVectors map[utils.UID]map[utils.UID]float32, threshold float32) []utils.UID {
-	result := []utils.UID{}
+func getTaskSimilarity(userVector map[utils.UID]float32, taskVector map[utils.UID]float32) float32 {
+	similarity := float32(0)
+	for tagID, tagWeight := range taskVector {
+		if val, ok := userVector[tagID]; ok {
+			similarity += tagWeight * val
+		}
+	}
+	return similarity
+}
+
+func getRecommendedTasks(userVector map[utils.UID]float32, tasksVectors map[utils.UID]map[utils.UID]float32, threshold float32) []ScoredTask {
+	result := []ScoredTask{}
 	similarity := float32(0)
 
 	for taskID, tagsMap := range tasksVectors {
 		//TODO: use go coroutines
-		similarity = 0
-		for tagID, tagWeight := range tagsMap {
-			if val, ok := userVector[tagID]; ok {
-				similarity += tagWeight * val
-			}
-		}
+		similarity = getTaskSimilarity(userVector, tagsMap)
 
 		if similarity >= threshold {
-			result = append(result, taskID)
+			result = append(result, ScoredTask{taskID, similarity})
 		}
 	}
 	return result
 }
 
-func (controller *TasksController) GetRecommendations(userID utils.UID) ([]repository.Task, error) {
-	threshold, err := strconv.ParseFloat(os.Getenv("SIMILARITY_THRESHOLD"), 32)
+// orders tasks by score and id descending
+// and returns the ones placed after the cursor
+func getTasksAfterCursor(tasks []ScoredTask, cursor *utils.Cursor) []ScoredTask {
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Score != tasks[j].Score {
+			return tasks[i].Score > tasks[j].Score
+		}
+		return tasks[i].ID > tasks[j].ID
+	})
+
+	if cursor == nil {
+		return tasks
+	}
+
+	for i, task := range tasks {
+		score := float64(task.Score)
+		if score < cursor.Score || (score == cursor.Score && task.ID < cursor.ID) {
+			return tasks[i:]
+		}
+	}
+
+	return []ScoredTask{}
+}
+
+// tasks with lower similarity to the user aren't recommended, every related task is by default
+func getSimilarityThreshold() (float32, error) {
+	threshold, err := utils.GetEnvFloat("SIMILARITY_THRESHOLD", 0)
+	if err != nil {
+		return 0, err
+	}
+
+	if threshold < 0 || threshold > 1 {
+		return 0, utils.MakeConfigError("SIMILARITY_THRESHOLD")
+	}
+
+	return float32(threshold), nil
+}
+
+// candidates of a recommendations request sorted by score
+type scoredRecommendations struct {
+	userVector map[utils.UID]float32
+	tasksTags  []repository.TaskTagLink
+	tasks      []ScoredTask
+}
+
+func (controller *TasksController) GetRecommendations(userID utils.UID, filters repository.FeedFilters, cursor *utils.Cursor, limit int, at time.Time) ([]repository.Task, *utils.Cursor, error) {
+	scored, err := controller.scoreRecommendations(userID, filters, at)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return controller.getRecommendationsPage(userID, scored, cursor, limit)
+}
+
+// only likes and tasks that existed at the reference time are used, so scores don't change between pages
+func (controller *TasksController) scoreRecommendations(userID utils.UID, filters repository.FeedFilters, at time.Time) (*scoredRecommendations, error) {
+	threshold, err := getSimilarityThreshold()
 	if err != nil {
 		return nil, err
 	}
 
-	userTags, err := controller.ProfileRepo.GetLikedTags(userID)
+	userTags, err := controller.ProfileRepo.GetLikedTags(userID, at)
 	if err != nil {
 		return nil, err
 	}
 
-	tasksTags, err := controller.TasksRepo.GetTasksTags(userID)
+	tasksTags, err := controller.TasksRepo.GetTasksTags(userID, at)
 	if err != nil {
 		return nil, err
 	}
@@ -325,11 +1064,256 @@ func (controller *TasksController) GetRecommendations(userID utils.UID) ([]repos
 	userVector := buildUserRecommendationsVector(userTags)
 	tasksVector := buildTasksRecommendationsVector(tasksTags)
 
-	recommendedTasks := getRecommendedTasks(userVector, tasksVector, float32(threshold))
-	result, err := controller.TasksRepo.GetTasks(userID, recommendedTasks)
+	//filters only narrow down candidates, tag weights are still computed over all tasks
+	if !filters.IsEmpty() {
+		tasksTags, err = controller.filterTasksTags(tasksTags, filters)
+		if err != nil {
+			return nil, err
+		}
+
+		filteredVector := map[utils.UID]map[utils.UID]float32{}
+		for _, row := range tasksTags {
+			filteredVector[row.TaskID] = tasksVector[row.TaskID]
+		}
+		tasksVector = filteredVector
+	}
+
+	recommendedTasks := getTasksAfterCursor(getRecommendedTasks(userVector, tasksVector, threshold), nil)
+	return &scoredRecommendations{userVector, tasksTags, recommendedTasks}, nil
+}
+
+func (scored *scoredRecommendations) tasksID() []utils.UID {
+	result := []utils.UID{}
+	for _, task := range scored.tasks {
+		result = append(result, task.ID)
+	}
+	return result
+}
+
+// position before the first recommended task, used after the first page if none of the recommended tasks were shown yet,
+// so the next page starts from the beginning without reserving exploration slots again
+func getRecommendationsStartCursor(tasks []ScoredTask) *utils.Cursor {
+	if len(tasks) == 0 {
+		return &utils.Cursor{End: true}
+	}
+	return &utils.Cursor{ID: tasks[0].ID + 1, Score: float64(tasks[0].Score)}
+}
+
+func (controller *TasksController) getRecommendationsPage(userID utils.UID, scored *scoredRecommendations, cursor *utils.Cursor, limit int) ([]repository.Task, *utils.Cursor, error) {
+	var err error
+
+	// slots are reserved only for the first page and taken out of its limit, so exploration tasks don't repeat on the next ones
+	explorationTasks := map[utils.UID]utils.UID{}
+	if cursor == nil {
+		explorationTasks, err = controller.getExplorationTasks(userID, scored.userVector, scored.tasksTags, scored.tasksID(), limit)
+		if err != nil {
+			return nil, nil, err
+		}
+		cursor = getRecommendationsStartCursor(scored.tasks)
+	}
+
+	page := getTasksAfterCursor(scored.tasks, cursor)
+	truncated := false
+	if recommendedLimit := limit - len(explorationTasks); len(page) > recommendedLimit {
+		page = page[:recommendedLimit]
+		truncated = true
+	}
+
+	//exploration tasks don't move the cursor, so they are placed after the scored ones
+	pageID := []utils.UID{}
+	cursors := map[utils.UID]*utils.Cursor{}
+	for _, task := range page {
+		pageID = append(pageID, task.ID)
+		cursor = &utils.Cursor{ID: task.ID, Score: float64(task.Score)}
+		cursors[task.ID] = cursor
+	}
+
+	for taskID := range explorationTasks {
+		pageID = append(pageID, taskID)
+		cursors[taskID] = cursor
+	}
+
+	var next *utils.Cursor
+	if truncated {
+		next = cursor
+	}
+
+	tasks, err := controller.TasksRepo.GetTasks(userID, pageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tasksMap := map[utils.UID]repository.Task{}
+	for _, task := range tasks {
+		task.Cursor = cursors[task.ID]
+		tasksMap[task.ID] = task
+	}
+
+	result := []repository.Task{}
+	for _, taskID := range pageID {
+		if task, ok := tasksMap[taskID]; ok {
+			result = append(result, task)
+		}
+	}
+
+	return result, next, nil
+}
+
+func (controller *TasksController) filterTasksTags(tasksTags []repository.TaskTagLink, filters repository.FeedFilters) ([]repository.TaskTagLink, error) {
+	uniqueTasks := map[utils.UID]struct{}{}
+	tasksID := []utils.UID{}
+	for _, row := range tasksTags {
+		if _, contains := uniqueTasks[row.TaskID]; !contains {
+			uniqueTasks[row.TaskID] = struct{}{}
+			tasksID = append(tasksID, row.TaskID)
+		}
+	}
+
+	filteredID, err := controller.TasksRepo.FilterTasks(tasksID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := map[utils.UID]struct{}{}
+	for _, taskID := range filteredID {
+		filtered[taskID] = struct{}{}
+	}
+
+	result := []repository.TaskTagLink{}
+	for _, row := range tasksTags {
+		if _, contains := filtered[row.TaskID]; contains {
+			result = append(result, row)
+		}
+	}
+
+	return result, nil
+}
+
+func (controller *TasksController) getExplorationTasks(userID utils.UID, userVector map[utils.UID]float32, tasksTags []repository.TaskTagLink, recommendedTasks []utils.UID, limit int) (map[utils.UID]utils.UID, error) {
+	rate, err := getExplorationRate()
+	if err != nil {
+		return nil, err
+	}
+
+	slots := getExplorationSlotsCount(rate, limit)
+	if slots == 0 {
+		return map[utils.UID]utils.UID{}, nil
+	}
+
+	stats, err := controller.ExplorationRepo.GetExplorationStats(userID)
 	if err != nil {
 		return nil, err
 	}
 
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	result := getExplorationTasks(rng, userVector, tasksTags, recommendedTasks, stats, slots)
+	for taskID, tagID := range result {
+		err = controller.ExplorationRepo.AddExplorationSlot(userID, taskID, tagID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return result, nil
 }
+
+// published drafts trigger the same notifications as newly created tasks
+func (controller *TasksController) NotifyTaskPublished(taskID utils.UID) error {
+	customerID, err := controller.TasksRepo.GetTaskCustomer(taskID)
+	if err != nil {
+		return err
+	}
+
+	tags, err := controller.TagsRepo.GetTaskTags(taskID)
+	if err != nil {
+		return err
+	}
+
+	tagsID := []utils.UID{}
+	for _, tag := range tags {
+		tagsID = append(tagsID, tag.ID)
+	}
+
+	return controller.NotifyTaskCreated(taskID, customerID, tagsID)
+}
+
+func (controller *TasksController) NotifyTaskCreated(taskID utils.UID, customerID utils.UID, tagsID []utils.UID) error {
+	subscribers, err := controller.SubscriptionsRepo.GetTaskSubscribers(taskID)
+	if err != nil {
+		return err
+	}
+
+	notifiedUsers := map[utils.UID]struct{}{}
+	for _, userID := range subscribers {
+		err = controller.NotificationsRepo.CreateNotification(userID, repository.SUBSCRIBED_TASK_NOTIFICATION, taskID)
+		if err != nil {
+			return err
+		}
+		notifiedUsers[userID] = struct{}{}
+	}
+
+	return controller.NotifyMatchingUsers(taskID, customerID, tagsID, notifiedUsers)
+}
+
+// similarity between user and task vectors needed to notify the user
+// and the number of such notifications a user can get per day
+func getMatchingTaskConfig() (float32, int, error) {
+	threshold, err := utils.GetEnvFloat("MATCHING_TASK_THRESHOLD", 0.5)
+	if err != nil {
+		return 0, 0, err
+	}
+	if threshold < 0 || threshold > 1 {
+		return 0, 0, utils.MakeConfigError("MATCHING_TASK_THRESHOLD")
+	}
+
+	dailyLimit, err := utils.GetEnvInt("MATCHING_TASK_DAILY_LIMIT", 5)
+	if err != nil {
+		return 0, 0, err
+	}
+	if dailyLimit < 0 {
+		return 0, 0, utils.MakeConfigError("MATCHING_TASK_DAILY_LIMIT")
+	}
+
+	return float32(threshold), dailyLimit, nil
+}
+
+func (controller *TasksController) NotifyMatchingUsers(taskID utils.UID, customerID utils.UID, tagsID []utils.UID, notifiedUsers map[utils.UID]struct{}) error {
+	threshold, dailyLimit, err := getMatchingTaskConfig()
+	if err != nil {
+		return err
+	}
+
+	usersTags, err := controller.ProfileRepo.GetUsersLikedTags(tagsID, customerID)
+	if err != nil {
+		return err
+	}
+
+	taskTags := []repository.TaskTagLink{}
+	for _, tagID := range tagsID {
+		taskTags = append(taskTags, repository.TaskTagLink{TaskID: taskID, TagID: tagID})
+	}
+	taskVector := buildTasksRecommendationsVector(taskTags)
+
+	usersLinks := map[utils.UID][]repository.TaskTagLink{}
+	for _, row := range usersTags {
+		usersLinks[row.UserID] = append(usersLinks[row.UserID], row.TaskTagLink)
+	}
+
+	for userID, userTags := range usersLinks {
+		if _, notified := notifiedUsers[userID]; notified {
+			continue
+		}
+
+		userVector := buildUserRecommendationsVector(userTags)
+		if len(getRecommendedTasks(userVector, taskVector, threshold)) == 0 {
+			continue
+		}
+
+		_, err = controller.NotificationsRepo.CreateLimitedNotification(userID, repository.MATCHING_TASK_NOTIFICATION, taskID, dailyLimit)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
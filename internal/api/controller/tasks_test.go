@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+func TestValidateTaskDeadline(t *testing.T) {
+	past := time.Now().Add(-24 * time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+	otherPast := past.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		deadline *time.Time
+		current  *time.Time
+		invalid  bool
+	}{
+		{"no deadline", nil, nil, false},
+		{"future deadline", &future, nil, false},
+		{"past deadline", &past, nil, true},
+		{"unchanged past deadline", &past, &past, false},
+		{"changed to past deadline", &otherPast, &past, true},
+		{"changed to future deadline", &future, &past, false},
+		{"removed deadline", nil, &past, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			task := InputTask{}
+			task.Deadline = test.deadline
+
+			err := validateTaskDeadline(task, test.current)
+			if (err != nil) != test.invalid {
+				t.Errorf("validateTaskDeadline() error = %v, want error %v", err, test.invalid)
+			}
+		})
+	}
+}
+
+func TestBindFeedCursor(t *testing.T) {
+	at := time.Now()
+	tests := []struct {
+		scope    string
+		snapshot bool
+	}{
+		{repository.NOT_ASSIGNED_TASKS, false},
+		{repository.CUSTOMER_TASKS, false},
+		{repository.TRENDING, true},
+		{repository.RECOMMENDATIONS, true},
+		{repository.HOME, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scope, func(t *testing.T) {
+			cursor := &utils.Cursor{ID: 1}
+			bindFeedCursor(cursor, repository.FeedRequest{Scope: test.scope, Sort: repository.NEWEST_FIRST, Query: "query", At: at})
+
+			if cursor.Scope != test.scope || cursor.Sort != repository.NEWEST_FIRST || cursor.Query != feedQueryKey("query") {
+				t.Errorf("bindFeedCursor() = %+v, want cursor bound to the request", cursor)
+			}
+
+			if (cursor.At != nil) != test.snapshot {
+				t.Errorf("bindFeedCursor() time = %v, want time bound %v", cursor.At, test.snapshot)
+			}
+		})
+	}
+}
+
+func TestGetMatchingTaskConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  string
+		dailyLimit string
+		want       float32
+		wantLimit  int
+		invalid    bool
+	}{
+		{"defaults", "", "", 0.5, 5, false},
+		{"custom", "0.8", "10", 0.8, 10, false},
+		{"notifications off", "", "0", 0.5, 0, false},
+		{"threshold above one", "1.5", "", 0, 0, true},
+		{"negative threshold", "-0.1", "", 0, 0, true},
+		{"negative limit", "", "-1", 0, 0, true},
+		{"not a number", "abc", "", 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("MATCHING_TASK_THRESHOLD", test.threshold)
+			t.Setenv("MATCHING_TASK_DAILY_LIMIT", test.dailyLimit)
+
+			threshold, dailyLimit, err := getMatchingTaskConfig()
+			if (err != nil) != test.invalid {
+				t.Fatalf("getMatchingTaskConfig() error = %v, want error %v", err, test.invalid)
+			}
+			if err == nil && (threshold != test.want || dailyLimit != test.wantLimit) {
+				t.Errorf("getMatchingTaskConfig() = %v, %v, want %v, %v", threshold, dailyLimit, test.want, test.wantLimit)
+			}
+		})
+	}
+}
+
+func TestGetSimilarityThreshold(t *testing.T) {
+	tests := []struct {
+		value     string
+		threshold float32
+		invalid   bool
+	}{
+		{"", 0, false},
+		{"0.3", 0.3, false},
+		{"1", 1, false},
+		{"1.2", 0, true},
+		{"-0.5", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Setenv("SIMILARITY_THRESHOLD", test.value)
+
+		threshold, err := getSimilarityThreshold()
+		if (err != nil) != test.invalid {
+			t.Errorf("getSimilarityThreshold() with %q error = %v, want error %v", test.value, err, test.invalid)
+		}
+		if err == nil && threshold != test.threshold {
+			t.Errorf("getSimilarityThreshold() with %q = %v, want %v", test.value, threshold, test.threshold)
+		}
+		if err != nil && utils.MakeInternalErrorMessage(err).Code != utils.CONFIG_ERROR {
+			t.Errorf("getSimilarityThreshold() with %q error isn't reported as %v", test.value, utils.CONFIG_ERROR)
+		}
+	}
+}
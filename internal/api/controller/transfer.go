@@ -0,0 +1,445 @@
+package controller
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+const (
+	JSONL_FORMAT = "jsonl"
+	CSV_FORMAT   = "csv"
+)
+
+const maxImportRows = 10000
+
+// tags are separated with this character in csv rows
+const csvTagsSeparator = "|"
+
+// id is ignored on import
+var csvColumns = []string{"id", "name", "description", "customer", "state", "tags", "createdAt", "budgetMin", "budgetMax", "currency", "deadline", "lat", "lon", "place"}
+
+// row format shared by import and export, customer defaults to the importing admin,
+// state defaults to open, assigned tasks can't be imported, since doers aren't exported
+type TransferTask struct {
+	ID          utils.UID            `json:"id,omitempty"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Customer    utils.UID            `json:"customer,omitempty"`
+	State       string               `json:"state,omitempty"`
+	Tags        []string             `json:"tags"`
+	Budget      *repository.Budget   `json:"budget,omitempty"`
+	Deadline    *time.Time           `json:"deadline,omitempty"`
+	Location    *repository.Location `json:"location,omitempty"`
+	CreatedAt   *time.Time           `json:"createdAt,omitempty"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ImportReport struct {
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+func (report *ImportReport) addError(row int, code string, err error) {
+	report.Failed++
+	report.Errors = append(report.Errors, ImportRowError{row, code, err.Error()})
+}
+
+func makeTransferTask(task repository.Task, tags []string) TransferTask {
+	result := TransferTask{
+		ID:          task.ID,
+		Name:        task.Name,
+		Description: task.Description,
+		Customer:    task.Customer.ID,
+		State:       task.State,
+		Tags:        tags,
+		Budget:      task.Budget,
+		Deadline:    task.Deadline,
+		Location:    task.Location,
+		CreatedAt:   &task.CreatedAt,
+	}
+
+	return result
+}
+
+func (task TransferTask) toInputTask(defaultCustomer utils.UID) InputTask {
+	input := InputTask{Tags: []repository.Tag{}}
+	input.Name = task.Name
+	input.State = task.State
+	input.Description = task.Description
+	input.Budget = task.Budget
+	input.Deadline = task.Deadline
+	input.Location = task.Location
+
+	input.Customer.ID = task.Customer
+	if input.Customer.ID == 0 {
+		input.Customer.ID = defaultCustomer
+	}
+
+	if task.CreatedAt != nil {
+		input.CreatedAt = *task.CreatedAt
+	}
+
+	for _, tag := range task.Tags {
+		input.Tags = append(input.Tags, repository.Tag{Text: tag})
+	}
+
+	return input
+}
+
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func parseCSVHeader(record []string) (map[string]int, error) {
+	known := map[string]struct{}{}
+	for _, column := range csvColumns {
+		known[column] = struct{}{}
+	}
+
+	result := map[string]int{}
+	for index, column := range record {
+		if _, ok := known[column]; !ok {
+			return nil, errors.New(utils.INVALID_INPUT)
+		}
+		result[column] = index
+	}
+
+	for _, column := range []string{"name", "description", "tags"} {
+		if _, ok := result[column]; !ok {
+			return nil, errors.New(utils.INVALID_INPUT)
+		}
+	}
+
+	return result, nil
+}
+
+func parseCSVTask(header map[string]int, record []string) (TransferTask, error) {
+	var err error
+	get := func(column string) string {
+		if index, ok := header[column]; ok && index < len(record) {
+			return strings.TrimSpace(record[index])
+		}
+		return ""
+	}
+
+	task := TransferTask{
+		Name:        get("name"),
+		Description: get("description"),
+		State:       get("state"),
+		Tags:        []string{},
+	}
+
+	if value := get("customer"); value != "" {
+		task.Customer, err = utils.UIDFromString(value)
+		if err != nil {
+			return task, err
+		}
+	}
+
+	for _, tag := range strings.Split(get("tags"), csvTagsSeparator) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+
+	task.CreatedAt, err = parseOptionalTime(get("createdAt"))
+	if err != nil {
+		return task, err
+	}
+
+	task.Deadline, err = parseOptionalTime(get("deadline"))
+	if err != nil {
+		return task, err
+	}
+
+	if currency := get("currency"); currency != "" {
+		budgetMin, err := strconv.ParseInt(get("budgetMin"), 10, 32)
+		if err != nil {
+			return task, err
+		}
+
+		budgetMax, err := strconv.ParseInt(get("budgetMax"), 10, 32)
+		if err != nil {
+			return task, err
+		}
+
+		task.Budget = &repository.Budget{Min: int32(budgetMin), Max: int32(budgetMax), Currency: currency}
+	}
+
+	if get("lat") != "" || get("lon") != "" {
+		task.Location = &repository.Location{Place: get("place")}
+		task.Location.Latitude, err = strconv.ParseFloat(get("lat"), 64)
+		if err != nil {
+			return task, err
+		}
+
+		task.Location.Longitude, err = strconv.ParseFloat(get("lon"), 64)
+		if err != nil {
+			return task, err
+		}
+	}
+
+	return task, nil
+}
+
+func makeCSVRecord(task TransferTask) []string {
+	record := make([]string, len(csvColumns))
+	for index, column := range csvColumns {
+		switch column {
+		case "id":
+			record[index] = task.ID.String()
+		case "name":
+			record[index] = task.Name
+		case "description":
+			record[index] = task.Description
+		case "customer":
+			record[index] = task.Customer.String()
+		case "state":
+			record[index] = task.State
+		case "tags":
+			record[index] = strings.Join(task.Tags, csvTagsSeparator)
+		case "createdAt":
+			if task.CreatedAt != nil {
+				record[index] = task.CreatedAt.Format(time.RFC3339)
+			}
+		case "budgetMin":
+			if task.Budget != nil {
+				record[index] = strconv.FormatInt(int64(task.Budget.Min), 10)
+			}
+		case "budgetMax":
+			if task.Budget != nil {
+				record[index] = strconv.FormatInt(int64(task.Budget.Max), 10)
+			}
+		case "currency":
+			if task.Budget != nil {
+				record[index] = task.Budget.Currency
+			}
+		case "deadline":
+			if task.Deadline != nil {
+				record[index] = task.Deadline.Format(time.RFC3339)
+			}
+		case "lat":
+			if task.Location != nil {
+				record[index] = strconv.FormatFloat(task.Location.Latitude, 'f', -1, 64)
+			}
+		case "lon":
+			if task.Location != nil {
+				record[index] = strconv.FormatFloat(task.Location.Longitude, 'f', -1, 64)
+			}
+		case "place":
+			if task.Location != nil {
+				record[index] = task.Location.Place
+			}
+		}
+	}
+
+	return record
+}
+
+func isImportState(state string) bool {
+	switch state {
+	case "", repository.TASK_OPEN, repository.TASK_DRAFT, repository.TASK_COMPLETED, repository.TASK_CANCELLED, repository.TASK_EXPIRED:
+		return true
+	}
+	return false
+}
+
+// imported tasks don't trigger new task notifications, since they aren't new for their customers
+func (controller *AdminController) importTask(r *http.Request, row int, task TransferTask, report *ImportReport) {
+	input := task.toInputTask(utils.GetUserID(r.Context()))
+
+	err := validateTask(input)
+	if err == nil && input.CreatedAt.After(time.Now()) {
+		err = errors.New(utils.INVALID_INPUT)
+	}
+
+	if err == nil && !isImportState(input.State) {
+		err = errors.New(utils.INVALID_INPUT)
+	}
+
+	if err != nil {
+		report.addError(row, utils.BAD_INPUT, err)
+		return
+	}
+
+	_, _, err = createTask(r.Context(), controller.SQLClient, controller.TasksRepo, controller.TagsRepo, input)
+	if err != nil {
+		report.addError(row, utils.SQL_ERROR, err)
+		return
+	}
+
+	report.Imported++
+}
+
+// row is either a parsed task or the error of its parsing
+type importRow struct {
+	Row  int
+	Task TransferTask
+	Code string
+	Err  error
+}
+
+// reads at most maxImportRows + 1 rows, so the limit is checked before anything is imported
+func readJSONL(body io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	result := []importRow{}
+	for len(result) <= maxImportRows && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		row := importRow{Row: len(result) + 1}
+		err := json.Unmarshal([]byte(line), &row.Task)
+		if err != nil {
+			row.Code, row.Err = utils.DECODER_ERROR, err
+		}
+
+		result = append(result, row)
+	}
+
+	return result, scanner.Err()
+}
+
+func readCSV(body io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+
+	record, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := parseCSVHeader(record)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []importRow{}
+	for len(result) <= maxImportRows {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		row := importRow{Row: len(result) + 1}
+		if err == nil {
+			row.Task, err = parseCSVTask(header, record)
+		}
+
+		if err != nil {
+			row.Code, row.Err = utils.DECODER_ERROR, err
+		}
+
+		result = append(result, row)
+	}
+
+	return result, nil
+}
+
+// the whole file is read before importing, so a malformed or oversized file doesn't leave a partial import behind,
+// rows are imported one by one, so failed rows don't prevent others from being imported
+func (controller *AdminController) HandleImportTasks(r *http.Request) utils.HandlerResponse {
+	var rows []importRow
+	var err error
+	switch r.URL.Query().Get("format") {
+	case "", JSONL_FORMAT:
+		rows, err = readJSONL(r.Body)
+	case CSV_FORMAT:
+		rows, err = readCSV(r.Body)
+	default:
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+	}
+
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	if len(rows) > maxImportRows {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+	}
+
+	report := ImportReport{Errors: []ImportRowError{}}
+	for _, row := range rows {
+		if row.Err != nil {
+			report.addError(row.Row, row.Code, row.Err)
+			continue
+		}
+
+		controller.importTask(r, row.Row, row.Task, &report)
+	}
+
+	return utils.MakeHandlerResponse(http.StatusOK, report, nil)
+}
+
+func (controller *AdminController) HandleExportTasks(r *http.Request) utils.HandlerResponse {
+	var err error
+	filters := repository.FeedFilters{}
+
+	filters.CreatedAfter, err = parseOptionalTime(r.FormValue("createdAfter"))
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	filters.CreatedBefore, err = parseOptionalTime(r.FormValue("createdBefore"))
+	if err != nil {
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+	}
+
+	switch r.FormValue("format") {
+	case "", JSONL_FORMAT:
+		return utils.MakeStreamResponse("application/x-ndjson; charset=UTF-8", func(w io.Writer) error {
+			encoder := json.NewEncoder(w)
+			return controller.TasksRepo.ExportTasks(filters, func(task repository.Task, tags []string) error {
+				return encoder.Encode(makeTransferTask(task, tags))
+			})
+		}).WithHeader("Content-Disposition", `attachment; filename="tasks.jsonl"`)
+	case CSV_FORMAT:
+		return utils.MakeStreamResponse("text/csv; charset=UTF-8", func(w io.Writer) error {
+			writer := csv.NewWriter(w)
+			err := writer.Write(csvColumns)
+			if err != nil {
+				return err
+			}
+
+			err = controller.TasksRepo.ExportTasks(filters, func(task repository.Task, tags []string) error {
+				return writer.Write(makeCSVRecord(makeTransferTask(task, tags)))
+			})
+			if err != nil {
+				return err
+			}
+
+			writer.Flush()
+			return writer.Error()
+		}).WithHeader("Content-Disposition", `attachment; filename="tasks.csv"`)
+	default:
+		return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+	}
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+)
+
+func TestCSVTaskRoundTrip(t *testing.T) {
+	createdAt := time.Date(2022, 3, 14, 9, 26, 53, 0, time.UTC)
+	deadline := time.Date(2022, 4, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		task TransferTask
+	}{
+		{
+			name: "required fields",
+			task: TransferTask{Name: "name", Description: "description", Customer: 12, State: repository.TASK_OPEN, Tags: []string{"go"}},
+		},
+		{
+			name: "all fields",
+			task: TransferTask{
+				Name:        "name, with \"quotes\"",
+				Description: "multiline\ndescription",
+				Customer:    34,
+				State:       repository.TASK_COMPLETED,
+				Tags:        []string{"go", "sql"},
+				Budget:      &repository.Budget{Min: 10, Max: 20, Currency: "EUR"},
+				Deadline:    &deadline,
+				Location:    &repository.Location{Latitude: 52.52, Longitude: -13.405, Place: "Berlin"},
+				CreatedAt:   &createdAt,
+			},
+		},
+		{
+			name: "no tags",
+			task: TransferTask{Name: "name", Description: "description", Customer: 56, Tags: []string{}},
+		},
+	}
+
+	header, err := parseCSVHeader(csvColumns)
+	if err != nil {
+		t.Fatalf("parseCSVHeader() error = %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			task, err := parseCSVTask(header, makeCSVRecord(test.task))
+			if err != nil {
+				t.Fatalf("parseCSVTask() error = %v", err)
+			}
+
+			// id is not imported
+			if !reflect.DeepEqual(task, test.task) {
+				t.Errorf("parseCSVTask(makeCSVRecord()) = %+v, want %+v", task, test.task)
+			}
+		})
+	}
+}
+
+func TestParseCSVTaskErrors(t *testing.T) {
+	header, err := parseCSVHeader(csvColumns)
+	if err != nil {
+		t.Fatalf("parseCSVHeader() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		column string
+		value  string
+	}{
+		{"invalid customer", "customer", "?"},
+		{"invalid created time", "createdAt", "yesterday"},
+		{"invalid deadline", "deadline", "2022-13-01"},
+		{"invalid budget", "budgetMin", "ten"},
+		{"invalid latitude", "lat", "north"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			record := makeCSVRecord(TransferTask{Name: "name", Description: "description", Tags: []string{"go"}, Budget: &repository.Budget{Min: 1, Max: 2, Currency: "EUR"}, Location: &repository.Location{}})
+			record[header[test.column]] = test.value
+
+			_, err := parseCSVTask(header, record)
+			if err == nil {
+				t.Errorf("parseCSVTask() with %s %q error = nil, want error", test.column, test.value)
+			}
+		})
+	}
+}
+
+func TestParseCSVHeader(t *testing.T) {
+	tests := []struct {
+		header  string
+		invalid bool
+	}{
+		{strings.Join(csvColumns, ","), false},
+		{"name,description,tags", false},
+		{"name,description", true},
+		{"name,description,tags,unknown", true},
+	}
+
+	for _, test := range tests {
+		_, err := parseCSVHeader(strings.Split(test.header, ","))
+		if (err != nil) != test.invalid {
+			t.Errorf("parseCSVHeader(%q) error = %v, want error %v", test.header, err, test.invalid)
+		}
+	}
+}
+
+func TestReadJSONLStopsAfterLimit(t *testing.T) {
+	line := `{"name":"name","description":"description","tags":["go"]}` + "\n"
+	rows, err := readJSONL(strings.NewReader(strings.Repeat(line, maxImportRows+5)))
+	if err != nil {
+		t.Fatalf("readJSONL() error = %v", err)
+	}
+
+	if len(rows) != maxImportRows+1 {
+		t.Errorf("readJSONL() read %d rows, want %d", len(rows), maxImportRows+1)
+	}
+}
@@ -1,8 +1,16 @@
 package middleware
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"net/http"
 
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
 	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
 	"github.com/st-matskevich/item-based-recommendations/internal/firebase"
 )
@@ -18,3 +26,153 @@ func AuthMiddleware(inner utils.BaseHandler) utils.BaseHandler {
 		return inner(r.WithContext(ctx))
 	})
 }
+
+func AdminMiddleware(profileRepo repository.ProfileRepository, inner utils.BaseHandler) utils.BaseHandler {
+	return utils.BaseHandler(func(r *http.Request) utils.HandlerResponse {
+		admin, err := profileRepo.IsAdmin(utils.GetUserID(r.Context()))
+		if err != nil {
+			return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		}
+
+		if !admin {
+			return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.AUTHORIZATION_ERROR), errors.New(utils.INSUFFICIENT_RIGHTS))
+		}
+
+		return inner(r)
+	})
+}
+
+func hashRequest(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// shared with the purge job, so keys are purged with the same retention they are kept for
+func GetIdempotencyRetentionHours() (int, error) {
+	retention, err := utils.GetEnvInt("IDEMPOTENCY_KEY_RETENTION_HOURS", 24)
+	if err != nil {
+		return 0, err
+	}
+
+	if retention < 1 {
+		return 0, utils.MakeConfigError("IDEMPOTENCY_KEY_RETENTION_HOURS")
+	}
+
+	return retention, nil
+}
+
+// reservation without a response is taken over after this timeout, so a key isn't stuck
+// if the process died while handling the request, should exceed the longest request duration
+func getIdempotencyLockSeconds() (int, error) {
+	timeout, err := utils.GetEnvInt("IDEMPOTENCY_KEY_LOCK_SECONDS", 60)
+	if err != nil {
+		return 0, err
+	}
+
+	if timeout < 1 {
+		return 0, utils.MakeConfigError("IDEMPOTENCY_KEY_LOCK_SECONDS")
+	}
+
+	return timeout, nil
+}
+
+// replays stored response for requests repeated with the same Idempotency-Key,
+// should be wrapped with AuthMiddleware since keys are scoped to the user
+func IdempotencyMiddleware(idempotencyRepo repository.IdempotencyRepository, inner utils.BaseHandler) utils.BaseHandler {
+	return utils.BaseHandler(func(r *http.Request) utils.HandlerResponse {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			return inner(r)
+		}
+
+		if len(key) > 255 {
+			return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.BAD_INPUT), errors.New(utils.INVALID_INPUT))
+		}
+
+		retention, err := GetIdempotencyRetentionHours()
+		if err != nil {
+			return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeInternalErrorMessage(err), err)
+		}
+
+		lockTimeout, err := getIdempotencyLockSeconds()
+		if err != nil {
+			return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeInternalErrorMessage(err), err)
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return utils.MakeHandlerResponse(http.StatusBadRequest, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		uid := utils.GetUserID(r.Context())
+		requestHash := hashRequest(r, body)
+
+		reserved, err := idempotencyRepo.ReserveKey(uid, key, requestHash, retention, lockTimeout)
+		if err != nil {
+			return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+		}
+
+		if !reserved {
+			record, err := idempotencyRepo.GetKey(uid, key)
+			if err != nil {
+				return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.SQL_ERROR), err)
+			}
+
+			if record.RequestHash != requestHash {
+				return utils.MakeHandlerResponse(http.StatusUnprocessableEntity, utils.MakeErrorMessage(utils.IDEMPOTENCY_KEY_REUSED), errors.New(utils.IDEMPOTENCY_MISMATCH))
+			}
+
+			if record.Code == 0 {
+				return utils.MakeHandlerResponse(http.StatusConflict, utils.MakeErrorMessage(utils.IDEMPOTENCY_KEY_IN_USE), errors.New(utils.IDEMPOTENCY_IN_PROGRESS))
+			}
+
+			response := utils.MakeHandlerResponse(record.Code, record.Body, nil)
+			err = json.Unmarshal([]byte(record.Headers), &response.Headers)
+			if err != nil {
+				return utils.MakeHandlerResponse(http.StatusInternalServerError, utils.MakeErrorMessage(utils.DECODER_ERROR), err)
+			}
+
+			return response.WithHeader("Idempotent-Replayed", "true")
+		}
+
+		// key is released if the handler panics, otherwise it stays in progress until it expires
+		defer func() {
+			if p := recover(); p != nil {
+				err := idempotencyRepo.DeleteKey(uid, key)
+				if err != nil {
+					log.Printf("Idempotency key release error: %v", err)
+				}
+				panic(p)
+			}
+		}()
+
+		response := inner(r)
+
+		// server errors are not stored, so the request can be retried with the same key
+		if response.Code >= http.StatusInternalServerError {
+			err = idempotencyRepo.DeleteKey(uid, key)
+			if err != nil && response.Err == nil {
+				response.Err = err
+			}
+			return response
+		}
+
+		responseBody, err := json.Marshal(response.Response)
+		if err == nil {
+			var responseHeaders []byte
+			responseHeaders, err = json.Marshal(response.Headers)
+			if err == nil {
+				err = idempotencyRepo.SaveResponse(uid, key, response.Code, string(responseBody), string(responseHeaders))
+			}
+		}
+
+		if err != nil && response.Err == nil {
+			response.Err = err
+		}
+
+		return response
+	})
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+	"github.com/st-matskevich/item-based-recommendations/internal/db"
+)
+
+type TagExplorationStats struct {
+	TagID    utils.UID
+	Shown    int32
+	Rewarded int32
+}
+
+type ExplorationRepository interface {
+	GetExplorationStats(userID utils.UID) ([]TagExplorationStats, error)
+	AddExplorationSlot(userID utils.UID, taskID utils.UID, tagID utils.UID) error
+	RewardExplorationSlot(userID utils.UID, taskID utils.UID) error
+	WithTx(tx *db.SQLClient) ExplorationRepository
+}
+
+type ExplorationSQLRepository struct {
+	SQLClient *db.SQLClient
+}
+
+func (repo *ExplorationSQLRepository) WithTx(tx *db.SQLClient) ExplorationRepository {
+	return &ExplorationSQLRepository{SQLClient: tx}
+}
+
+func (repo *ExplorationSQLRepository) GetExplorationStats(userID utils.UID) ([]TagExplorationStats, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT exploration_slots.tag_id, COUNT(*), COUNT(*) FILTER (WHERE exploration_slots.rewarded)
+		FROM exploration_slots
+		WHERE exploration_slots.user_id = $1
+		GROUP BY exploration_slots.tag_id`, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := []TagExplorationStats{}
+	row := TagExplorationStats{}
+	for {
+		ok, err := reader.NextRow(&row.TagID, &row.Shown, &row.Rewarded)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		result = append(result, row)
+	}
+
+	return result, nil
+}
+
+func (repo *ExplorationSQLRepository) AddExplorationSlot(userID utils.UID, taskID utils.UID, tagID utils.UID) error {
+	return repo.SQLClient.Exec(
+		`INSERT INTO exploration_slots(user_id, task_id, tag_id) VALUES ($1, $2, $3)
+		ON CONFLICT ON CONSTRAINT exploration_slots_user_task DO NOTHING`, userID, taskID, tagID,
+	)
+}
+
+func (repo *ExplorationSQLRepository) RewardExplorationSlot(userID utils.UID, taskID utils.UID) error {
+	return repo.SQLClient.Exec("UPDATE exploration_slots SET rewarded = true WHERE user_id = $1 AND task_id = $2", userID, taskID)
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+	"github.com/st-matskevich/item-based-recommendations/internal/db"
+)
+
+// Code is 0 while the first request with the key is still being processed
+type IdempotencyRecord struct {
+	RequestHash string
+	Code        int
+	Body        utils.JSONObject
+	Headers     string
+}
+
+type IdempotencyRepository interface {
+	ReserveKey(userID utils.UID, key string, requestHash string, retentionHours int, lockSeconds int) (bool, error)
+	GetKey(userID utils.UID, key string) (*IdempotencyRecord, error)
+	SaveResponse(userID utils.UID, key string, code int, body string, headers string) error
+	DeleteKey(userID utils.UID, key string) error
+	PurgeExpiredKeys(retentionHours int) error
+	WithTx(tx *db.SQLClient) IdempotencyRepository
+}
+
+type IdempotencySQLRepository struct {
+	SQLClient *db.SQLClient
+}
+
+func (repo *IdempotencySQLRepository) WithTx(tx *db.SQLClient) IdempotencyRepository {
+	return &IdempotencySQLRepository{SQLClient: tx}
+}
+
+// stores the key if it's new or the previous one is older than the retention window,
+// created_at is the reservation time, so the same request can take over a reservation that got no response
+// within lockSeconds, returns false if the key is already used
+func (repo *IdempotencySQLRepository) ReserveKey(userID utils.UID, key string, requestHash string, retentionHours int, lockSeconds int) (bool, error) {
+	reader, err := repo.SQLClient.Query(
+		`INSERT INTO idempotency_keys(user_id, key, request_hash) VALUES ($1, $2, $3)
+		ON CONFLICT ON CONSTRAINT idempotency_keys_user_key DO UPDATE
+		SET request_hash = $3, response_code = NULL, response_body = NULL, response_headers = NULL, created_at = now()
+		WHERE idempotency_keys.created_at < now() - make_interval(hours => $4)
+		OR (idempotency_keys.response_code IS NULL AND idempotency_keys.request_hash = $3 AND idempotency_keys.created_at < now() - make_interval(secs => $5))
+		RETURNING key`, userID, key, requestHash, retentionHours, lockSeconds,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	row := ""
+	return reader.NextRow(&row)
+}
+
+func (repo *IdempotencySQLRepository) GetKey(userID utils.UID, key string) (*IdempotencyRecord, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT request_hash, COALESCE(response_code, 0), COALESCE(response_body, 'null'), COALESCE(response_headers, '{}')
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2`, userID, key,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	row := IdempotencyRecord{}
+	found, err := reader.NextRow(&row.RequestHash, &row.Code, &row.Body, &row.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+
+	return &row, nil
+}
+
+func (repo *IdempotencySQLRepository) SaveResponse(userID utils.UID, key string, code int, body string, headers string) error {
+	return repo.SQLClient.Exec(
+		`UPDATE idempotency_keys SET response_code = $3, response_body = $4, response_headers = $5
+		WHERE user_id = $1 AND key = $2`, userID, key, code, body, headers,
+	)
+}
+
+func (repo *IdempotencySQLRepository) DeleteKey(userID utils.UID, key string) error {
+	return repo.SQLClient.Exec("DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2", userID, key)
+}
+
+func (repo *IdempotencySQLRepository) PurgeExpiredKeys(retentionHours int) error {
+	return repo.SQLClient.Exec("DELETE FROM idempotency_keys WHERE created_at < now() - make_interval(hours => $1)", retentionHours)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
@@ -16,23 +17,32 @@ type Notification struct {
 }
 
 const (
-	TASK_CLOSE_NOTIFICATION = 0
-	NEW_REPLY_NOTIFICATION  = 10000
+	TASK_CLOSE_NOTIFICATION      = 0
+	MATCHING_TASK_NOTIFICATION   = 1
+	SUBSCRIBED_TASK_NOTIFICATION = 2
+	TASK_EXPIRED_NOTIFICATION    = 3
+	NEW_REPLY_NOTIFICATION       = 10000
 )
 
 type NotificationsRepository interface {
 	GetNotifications(userID utils.UID) ([]Notification, error)
 	CreateNotification(userID utils.UID, notificationType int, triggerID utils.UID) error
+	CreateLimitedNotification(userID utils.UID, notificationType int, triggerID utils.UID, dailyLimit int) (bool, error)
+	WithTx(tx *db.SQLClient) NotificationsRepository
 }
 
 type NotificationsSQLRepository struct {
 	SQLClient *db.SQLClient
 }
 
+func (repo *NotificationsSQLRepository) WithTx(tx *db.SQLClient) NotificationsRepository {
+	return &NotificationsSQLRepository{SQLClient: tx}
+}
+
 func (repo *NotificationsSQLRepository) GetNotifications(userID utils.UID) ([]Notification, error) {
 	reader, err := repo.SQLClient.Query(
 		`SELECT notifications.notification_id, notifications.type, notifications.created_at,
-		(CASE WHEN notifications.type=0 THEN JSON_BUILD_OBJECT('id', ENCODE(task_trigger.task_id::text::bytea, 'base64'), 'name', task_trigger.name, 'customer', JSON_BUILD_OBJECT('name', users.name))
+		(CASE WHEN notifications.type IN (0, 1, 2, 3) THEN JSON_BUILD_OBJECT('id', ENCODE(task_trigger.task_id::text::bytea, 'base64'), 'name', task_trigger.name, 'customer', JSON_BUILD_OBJECT('name', users.name))
 		WHEN notifications.type=10000 THEN JSON_BUILD_OBJECT('task', JSON_BUILD_OBJECT('id', ENCODE(tasks.task_id::text::bytea, 'base64'), 'name', tasks.name), 'reply', JSON_BUILD_OBJECT('creator', JSON_BUILD_OBJECT('name', users.name), 'text', reply_trigger.text))
 		ELSE (NULL) END) AS content
 		FROM notifications
@@ -46,6 +56,8 @@ func (repo *NotificationsSQLRepository) GetNotifications(userID utils.UID) ([]No
 		ON task_trigger.customer_id = users.user_id
 		OR reply_trigger.creator_id = users.user_id
 		WHERE notifications.user_id = $1
+		AND task_trigger.deleted_at IS NULL
+		AND tasks.deleted_at IS NULL
 		ORDER BY notifications.notification_id DESC`, userID,
 	)
 	if err != nil {
@@ -73,3 +85,37 @@ func (repo *NotificationsSQLRepository) GetNotifications(userID utils.UID) ([]No
 func (repo *NotificationsSQLRepository) CreateNotification(userID utils.UID, notificationType int, triggerID utils.UID) error {
 	return repo.SQLClient.Exec("INSERT INTO notifications(user_id, type, trigger_id) VALUES ($1, $2, $3)", userID, notificationType, triggerID)
 }
+
+// per user advisory lock is taken before counting, so concurrent inserts can't exceed the limit,
+// lock is released with the transaction
+func (repo *NotificationsSQLRepository) CreateLimitedNotification(userID utils.UID, notificationType int, triggerID utils.UID, dailyLimit int) (bool, error) {
+	created := false
+	err := repo.SQLClient.WithTx(context.Background(), func(tx *db.SQLClient) error {
+		err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('limited_notifications'), hashtext($1::bigint::text))", userID)
+		if err != nil {
+			return err
+		}
+
+		reader, err := tx.Query(
+			`INSERT INTO notifications(user_id, type, trigger_id)
+			SELECT $1::bigint, $2::integer, $3::bigint
+			WHERE (
+				SELECT COUNT(*) FROM notifications
+				WHERE notifications.user_id = $1
+				AND notifications.type = $2
+				AND notifications.created_at >= DATE_TRUNC('day', now())
+			) < $4
+			RETURNING notification_id`, userID, notificationType, triggerID, dailyLimit,
+		)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		row := utils.UID(0)
+		created, err = reader.NextRow(&row)
+		return err
+	})
+
+	return created, err
+}
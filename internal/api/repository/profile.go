@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"time"
+
+	"github.com/lib/pq"
 	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
 	"github.com/st-matskevich/item-based-recommendations/internal/db"
 )
@@ -14,13 +17,20 @@ type UserData struct {
 type ProfileRepository interface {
 	GetProfile(userID utils.UID) (*UserData, error)
 	SetProfile(userID utils.UID, profile UserData) error
-	GetLikedTags(userID utils.UID) ([]TaskTagLink, error)
+	GetLikedTags(userID utils.UID, likedBefore time.Time) ([]TaskTagLink, error)
+	GetUsersLikedTags(tagsID []utils.UID, excludedUserID utils.UID) ([]UserTaskTagLink, error)
+	IsAdmin(userID utils.UID) (bool, error)
+	WithTx(tx *db.SQLClient) ProfileRepository
 }
 
 type ProfileSQLRepository struct {
 	SQLClient *db.SQLClient
 }
 
+func (repo *ProfileSQLRepository) WithTx(tx *db.SQLClient) ProfileRepository {
+	return &ProfileSQLRepository{SQLClient: tx}
+}
+
 func (repo *ProfileSQLRepository) GetProfile(userID utils.UID) (*UserData, error) {
 	reader, err := repo.SQLClient.Query("SELECT name, is_customer FROM users WHERE user_id = $1", userID)
 	if err != nil {
@@ -41,13 +51,30 @@ func (repo *ProfileSQLRepository) SetProfile(userID utils.UID, profile UserData)
 	return repo.SQLClient.Exec("UPDATE users SET name = $2, is_customer = $3 WHERE user_id = $1", userID, profile.Name, profile.IsCustomer)
 }
 
-func (repo *ProfileSQLRepository) GetLikedTags(userID utils.UID) ([]TaskTagLink, error) {
+func (repo *ProfileSQLRepository) IsAdmin(userID utils.UID) (bool, error) {
+	reader, err := repo.SQLClient.Query("SELECT is_admin FROM users WHERE user_id = $1", userID)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	result := false
+	err = reader.GetRow(&result)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+func (repo *ProfileSQLRepository) GetLikedTags(userID utils.UID, likedBefore time.Time) ([]TaskTagLink, error) {
 	reader, err := repo.SQLClient.Query(
 		`SELECT task_tag.task_id, task_tag.tag_id 
 		FROM likes 
 		JOIN task_tag 
 		ON likes.task_id = task_tag.task_id 
-		AND likes.user_id = $1 AND likes.active = true`, userID,
+		AND likes.user_id = $1 AND likes.active = true
+		AND likes.created_at <= $2::timestamptz`, userID, likedBefore,
 	)
 
 	if err != nil {
@@ -71,3 +98,43 @@ func (repo *ProfileSQLRepository) GetLikedTags(userID utils.UID) ([]TaskTagLink,
 
 	return result, nil
 }
+
+func (repo *ProfileSQLRepository) GetUsersLikedTags(tagsID []utils.UID, excludedUserID utils.UID) ([]UserTaskTagLink, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT likes.user_id, task_tag.task_id, task_tag.tag_id
+		FROM likes
+		JOIN task_tag
+		ON likes.task_id = task_tag.task_id
+		AND likes.active = true
+		WHERE likes.user_id <> $2
+		AND likes.user_id IN (
+			SELECT DISTINCT likes.user_id
+			FROM likes
+			JOIN task_tag
+			ON likes.task_id = task_tag.task_id
+			AND likes.active = true
+			WHERE task_tag.tag_id = ANY($1)
+		)`, pq.Array(tagsID), excludedUserID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := []UserTaskTagLink{}
+	row := UserTaskTagLink{}
+	for {
+		ok, err := reader.NextRow(&row.UserID, &row.TaskID, &row.TagID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		result = append(result, row)
+	}
+
+	return result, nil
+}
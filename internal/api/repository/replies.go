@@ -13,6 +13,7 @@ type Reply struct {
 	Creator   UserData  `json:"creator"`
 	TaskID    utils.UID `json:"taskId"`
 	CreatedAt time.Time `json:"createdAt"`
+	Hidden    bool      `json:"-"`
 }
 
 type RepliesRepository interface {
@@ -23,18 +24,24 @@ type RepliesRepository interface {
 	GetReply(replyID utils.UID) (*Reply, error)
 	CreateReply(taskID utils.UID, reply Reply) (utils.UID, error)
 	HideReply(replyID utils.UID) error
+	WithTx(tx *db.SQLClient) RepliesRepository
 }
 
 type RepliesSQLRepository struct {
 	SQLClient *db.SQLClient
 }
 
+func (repo *RepliesSQLRepository) WithTx(tx *db.SQLClient) RepliesRepository {
+	return &RepliesSQLRepository{SQLClient: tx}
+}
+
 func (repo *RepliesSQLRepository) GetReplies(taskID utils.UID) ([]Reply, error) {
 	reader, err := repo.SQLClient.Query(
 		`SELECT replies.reply_id, replies.text, users.user_id, users.name, replies.task_id, replies.created_at
 		FROM tasks JOIN replies 
 		ON tasks.task_id = replies.task_id
 		AND tasks.task_id = $1
+		AND tasks.deleted_at IS NULL
 		AND replies.hidden = false
 		JOIN users
 		ON replies.creator_id = users.user_id
@@ -68,6 +75,7 @@ func (repo *RepliesSQLRepository) GetRepliesCount(taskID utils.UID) (int32, erro
 		FROM tasks JOIN replies 
 		ON tasks.task_id = replies.task_id
 		AND tasks.task_id = $1
+		AND tasks.deleted_at IS NULL
 		AND replies.hidden = false`, taskID,
 	)
 	if err != nil {
@@ -90,6 +98,7 @@ func (repo *RepliesSQLRepository) GetDoerReply(taskID utils.UID) (*Reply, error)
 		FROM tasks JOIN replies 
 		ON tasks.task_id = replies.task_id
 		AND tasks.task_id = $1
+		AND tasks.deleted_at IS NULL
 		AND tasks.doer_id = replies.creator_id
 		JOIN users
 		ON replies.creator_id = users.user_id`, taskID,
@@ -118,6 +127,7 @@ func (repo *RepliesSQLRepository) GetUserReply(taskID utils.UID, userID utils.UI
 		FROM tasks JOIN replies 
 		ON tasks.task_id = replies.task_id
 		AND tasks.task_id = $1
+		AND tasks.deleted_at IS NULL
 		AND replies.creator_id = $2
 		JOIN users
 		ON replies.creator_id = users.user_id`, taskID, userID,
@@ -162,7 +172,7 @@ func (repo *RepliesSQLRepository) HideReply(replyID utils.UID) error {
 
 func (repo *RepliesSQLRepository) GetReply(replyID utils.UID) (*Reply, error) {
 	reader, err := repo.SQLClient.Query(
-		`SELECT replies.reply_id, replies.text, users.user_id, users.name, replies.task_id, replies.created_at
+		`SELECT replies.reply_id, replies.text, users.user_id, users.name, replies.task_id, replies.created_at, replies.hidden
 		FROM replies 
 		JOIN users
 		ON replies.creator_id = users.user_id
@@ -174,7 +184,7 @@ func (repo *RepliesSQLRepository) GetReply(replyID utils.UID) (*Reply, error) {
 	defer reader.Close()
 
 	row := Reply{}
-	err = reader.GetRow(&row.ID, &row.Text, &row.Creator.ID, &row.Creator.Name, &row.TaskID, &row.CreatedAt)
+	err = reader.GetRow(&row.ID, &row.Text, &row.Creator.ID, &row.Creator.Name, &row.TaskID, &row.CreatedAt, &row.Hidden)
 	if err != nil {
 		return nil, err
 	}
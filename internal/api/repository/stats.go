@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+	"github.com/st-matskevich/item-based-recommendations/internal/db"
+)
+
+// likes and replies are counted by the day they were created, only the active likes and visible replies are counted,
+// so the daily series add up to the totals
+type TaskDailyStats struct {
+	Day     string `json:"day"`
+	Views   int32  `json:"views"`
+	Likes   int32  `json:"likes"`
+	Replies int32  `json:"replies"`
+}
+
+type TaskStats struct {
+	Views   int32            `json:"views"`
+	Likes   int32            `json:"likes"`
+	Replies int32            `json:"replies"`
+	Daily   []TaskDailyStats `json:"daily"`
+}
+
+type StatsRepository interface {
+	AddTaskView(taskID utils.UID, userID utils.UID) error
+	GetTaskStats(taskID utils.UID, days int) (*TaskStats, error)
+	WithTx(tx *db.SQLClient) StatsRepository
+}
+
+type StatsSQLRepository struct {
+	SQLClient *db.SQLClient
+}
+
+func (repo *StatsSQLRepository) WithTx(tx *db.SQLClient) StatsRepository {
+	return &StatsSQLRepository{SQLClient: tx}
+}
+
+// views are counted once per user per day
+func (repo *StatsSQLRepository) AddTaskView(taskID utils.UID, userID utils.UID) error {
+	return repo.SQLClient.Exec(
+		`INSERT INTO task_views(task_id, user_id) VALUES ($1, $2)
+		ON CONFLICT ON CONSTRAINT task_views_task_user_day DO NOTHING`, taskID, userID,
+	)
+}
+
+func (repo *StatsSQLRepository) GetTaskStats(taskID utils.UID, days int) (*TaskStats, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT (SELECT COUNT(*) FROM task_views WHERE task_views.task_id = $1),
+		(SELECT tasks.likes_count FROM tasks WHERE tasks.task_id = $1),
+		(SELECT COUNT(*) FROM replies WHERE replies.task_id = $1 AND replies.hidden = false)`, taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := TaskStats{}
+	err = reader.GetRow(&result.Views, &result.Likes, &result.Replies)
+	if err != nil {
+		return nil, err
+	}
+	reader.Close()
+
+	dailyReader, err := repo.SQLClient.Query(
+		`SELECT TO_CHAR(days.day, 'YYYY-MM-DD'),
+		(SELECT COUNT(*) FROM task_views WHERE task_views.task_id = $1 AND task_views.day = days.day::date),
+		(SELECT COUNT(*) FROM likes WHERE likes.task_id = $1 AND likes.active = true AND likes.created_at >= days.day AND likes.created_at < days.day + INTERVAL '1 day'),
+		(SELECT COUNT(*) FROM replies WHERE replies.task_id = $1 AND replies.hidden = false AND replies.created_at >= days.day AND replies.created_at < days.day + INTERVAL '1 day')
+		FROM generate_series(CURRENT_DATE - ($2::integer - 1), CURRENT_DATE, INTERVAL '1 day') AS days(day)
+		ORDER BY days.day`, taskID, days,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer dailyReader.Close()
+
+	result.Daily = []TaskDailyStats{}
+	row := TaskDailyStats{}
+	for {
+		ok, err := dailyReader.NextRow(&row.Day, &row.Views, &row.Likes, &row.Replies)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		result.Daily = append(result.Daily, row)
+	}
+
+	return &result, nil
+}
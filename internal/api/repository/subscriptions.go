@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+	"github.com/st-matskevich/item-based-recommendations/internal/db"
+)
+
+type SavedSearch struct {
+	ID        utils.UID        `json:"id"`
+	Scope     string           `json:"scope"`
+	Query     string           `json:"query"`
+	Tags      utils.JSONObject `json:"tags"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+// saved searches are matched against tasks of other users, so only the scopes
+// that don't depend on the user's own tasks can be saved
+func IsSavedSearchScope(scope string) bool {
+	switch scope {
+	case NOT_ASSIGNED_TASKS, TRENDING:
+		return true
+	}
+	return false
+}
+
+// applies saved_searches.scope to tasks row, trending searches don't match tasks
+// on creation, since they have no engagement yet, but match them in the subscribed feed later
+var savedSearchScopeFilter = `(tasks.state = '` + TASK_OPEN + `' AND (
+		(saved_searches.scope = '` + NOT_ASSIGNED_TASKS + `' AND tasks.doer_id IS NULL)
+		OR (saved_searches.scope = '` + TRENDING + `' AND task_trending_score(tasks.task_id, tasks.published_at, ` + strconv.Itoa(TRENDING_WINDOW_HOURS) + `, ` + strconv.Itoa(TRENDING_HALF_LIFE_HOURS) + `, now()::timestamp) > 0)
+	))`
+
+// matches open tasks with at least one subscribed tag
+// or satisfying one of the saved searches of user $1
+var subscribedTaskFilter = `(EXISTS (
+		SELECT 1
+		FROM tag_subscriptions
+		JOIN task_tag AS subscribed_tag
+		ON tag_subscriptions.tag_id = subscribed_tag.tag_id
+		WHERE tag_subscriptions.user_id = $1
+		AND subscribed_tag.task_id = tasks.task_id
+	) OR EXISTS (
+		SELECT 1
+		FROM saved_searches
+		WHERE saved_searches.user_id = $1
+		AND ` + savedSearchScopeFilter + `
+		AND (saved_searches.query = '' OR tasks.search_vector @@ websearch_to_tsquery('simple', saved_searches.query))
+		AND saved_searches.tags <@ ARRAY(SELECT searched_tag.tag_id FROM task_tag AS searched_tag WHERE searched_tag.task_id = tasks.task_id)
+	))`
+
+type SubscriptionsRepository interface {
+	GetSubscribedTags(userID utils.UID) ([]Tag, error)
+	SubscribeToTag(userID utils.UID, tagID utils.UID) (bool, error)
+	UnsubscribeFromTag(userID utils.UID, tagID utils.UID) error
+	GetSavedSearches(userID utils.UID) ([]SavedSearch, error)
+	GetSavedSearch(userID utils.UID, searchID utils.UID) (*SavedSearch, error)
+	CreateSavedSearch(userID utils.UID, search SavedSearch, tagsID []utils.UID) (utils.UID, error)
+	DeleteSavedSearch(userID utils.UID, searchID utils.UID) error
+	GetTaskSubscribers(taskID utils.UID) ([]utils.UID, error)
+	WithTx(tx *db.SQLClient) SubscriptionsRepository
+}
+
+type SubscriptionsSQLRepository struct {
+	SQLClient *db.SQLClient
+}
+
+func (repo *SubscriptionsSQLRepository) WithTx(tx *db.SQLClient) SubscriptionsRepository {
+	return &SubscriptionsSQLRepository{SQLClient: tx}
+}
+
+func (repo *SubscriptionsSQLRepository) GetSubscribedTags(userID utils.UID) ([]Tag, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT tags.tag_id, tags.text
+		FROM tag_subscriptions JOIN tags
+		ON tag_subscriptions.tag_id = tags.tag_id
+		AND tag_subscriptions.user_id = $1
+		ORDER BY tags.text`, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	tags := []Tag{}
+	row := Tag{}
+	for {
+		ok, err := reader.NextRow(&row.ID, &row.Text)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		tags = append(tags, row)
+	}
+
+	return tags, nil
+}
+
+// returns false if the tag doesn't exist
+func (repo *SubscriptionsSQLRepository) SubscribeToTag(userID utils.UID, tagID utils.UID) (bool, error) {
+	reader, err := repo.SQLClient.Query(
+		`WITH target AS (
+			SELECT tags.tag_id FROM tags WHERE tags.tag_id = $2
+		), subscribed AS (
+			INSERT INTO tag_subscriptions(user_id, tag_id) SELECT $1, target.tag_id FROM target
+			ON CONFLICT ON CONSTRAINT tag_subscriptions_user_tag DO NOTHING
+		)
+		SELECT tag_id FROM target`, userID, tagID,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	row := utils.UID(0)
+	return reader.NextRow(&row)
+}
+
+func (repo *SubscriptionsSQLRepository) UnsubscribeFromTag(userID utils.UID, tagID utils.UID) error {
+	return repo.SQLClient.Exec("DELETE FROM tag_subscriptions WHERE user_id = $1 AND tag_id = $2", userID, tagID)
+}
+
+const savedSearchColumns = `saved_searches.search_id, saved_searches.scope, saved_searches.query,
+	COALESCE((SELECT JSON_AGG(JSONB_BUILD_OBJECT('id', ENCODE(tags.tag_id::text::bytea, 'base64'), 'text', tags.text)) FROM tags WHERE tags.tag_id = ANY(saved_searches.tags)), '[]'),
+	saved_searches.created_at`
+
+func (repo *SubscriptionsSQLRepository) GetSavedSearches(userID utils.UID) ([]SavedSearch, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT `+savedSearchColumns+`
+		FROM saved_searches
+		WHERE saved_searches.user_id = $1
+		ORDER BY saved_searches.search_id DESC`, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	searches := []SavedSearch{}
+	row := SavedSearch{}
+	for {
+		ok, err := reader.NextRow(&row.ID, &row.Scope, &row.Query, &row.Tags, &row.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		searches = append(searches, row)
+	}
+
+	return searches, nil
+}
+
+func (repo *SubscriptionsSQLRepository) GetSavedSearch(userID utils.UID, searchID utils.UID) (*SavedSearch, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT `+savedSearchColumns+`
+		FROM saved_searches
+		WHERE saved_searches.user_id = $1
+		AND saved_searches.search_id = $2`, userID, searchID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	row := SavedSearch{}
+	err = reader.GetRow(&row.ID, &row.Scope, &row.Query, &row.Tags, &row.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &row, nil
+}
+
+func (repo *SubscriptionsSQLRepository) CreateSavedSearch(userID utils.UID, search SavedSearch, tagsID []utils.UID) (utils.UID, error) {
+	reader, err := repo.SQLClient.Query(
+		"INSERT INTO saved_searches(user_id, scope, query, tags) VALUES ($1, $2, $3, $4) RETURNING search_id",
+		userID, search.Scope, search.Query, pq.Array(tagsID),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	row := utils.UID(0)
+	err = reader.GetRow(&row)
+	if err != nil {
+		return 0, err
+	}
+
+	return row, nil
+}
+
+func (repo *SubscriptionsSQLRepository) DeleteSavedSearch(userID utils.UID, searchID utils.UID) error {
+	return repo.SQLClient.Exec("DELETE FROM saved_searches WHERE search_id = $2 AND user_id = $1", userID, searchID)
+}
+
+func (repo *SubscriptionsSQLRepository) GetTaskSubscribers(taskID utils.UID) ([]utils.UID, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT tag_subscriptions.user_id
+		FROM tag_subscriptions JOIN task_tag
+		ON tag_subscriptions.tag_id = task_tag.tag_id
+		AND task_tag.task_id = $1
+		JOIN tasks
+		ON tasks.task_id = task_tag.task_id
+		AND tasks.customer_id <> tag_subscriptions.user_id
+		UNION
+		SELECT saved_searches.user_id
+		FROM saved_searches JOIN tasks
+		ON tasks.task_id = $1
+		AND tasks.customer_id <> saved_searches.user_id
+		AND `+savedSearchScopeFilter+`
+		AND (saved_searches.query = '' OR tasks.search_vector @@ websearch_to_tsquery('simple', saved_searches.query))
+		AND saved_searches.tags <@ ARRAY(SELECT task_tag.tag_id FROM task_tag WHERE task_tag.task_id = tasks.task_id)`, taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := []utils.UID{}
+	row := utils.UID(0)
+	for {
+		ok, err := reader.NextRow(&row)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		result = append(result, row)
+	}
+
+	return result, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"github.com/lib/pq"
 	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
 	"github.com/st-matskevich/item-based-recommendations/internal/db"
 )
@@ -15,17 +16,29 @@ type TaskTagLink struct {
 	TagID  utils.UID
 }
 
+type UserTaskTagLink struct {
+	UserID utils.UID
+	TaskTagLink
+}
+
 type TagsRepository interface {
 	GetTaskTags(taskID utils.UID) ([]Tag, error)
+	GetTags(tagsID []utils.UID) ([]Tag, error)
 	SearchTags(request string) ([]Tag, error)
 	CreateTag(tag string) (utils.UID, error)
 	AddTagToTask(taskID utils.UID, tagID utils.UID) error
+	RemoveTagFromTask(taskID utils.UID, tagID utils.UID) error
+	WithTx(tx *db.SQLClient) TagsRepository
 }
 
 type TagsSQLRepository struct {
 	SQLClient *db.SQLClient
 }
 
+func (repo *TagsSQLRepository) WithTx(tx *db.SQLClient) TagsRepository {
+	return &TagsSQLRepository{SQLClient: tx}
+}
+
 func (repo *TagsSQLRepository) GetTaskTags(taskID utils.UID) ([]Tag, error) {
 	reader, err := repo.SQLClient.Query(
 		`SELECT tags.tag_id, tags.text
@@ -55,6 +68,30 @@ func (repo *TagsSQLRepository) GetTaskTags(taskID utils.UID) ([]Tag, error) {
 	return tags, nil
 }
 
+func (repo *TagsSQLRepository) GetTags(tagsID []utils.UID) ([]Tag, error) {
+	reader, err := repo.SQLClient.Query("SELECT tags.tag_id, tags.text FROM tags WHERE tags.tag_id = ANY($1)", pq.Array(tagsID))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	tags := []Tag{}
+	row := Tag{}
+	for {
+		ok, err := reader.NextRow(&row.ID, &row.Text)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		tags = append(tags, row)
+	}
+
+	return tags, nil
+}
+
 func (repo *TagsSQLRepository) SearchTags(request string) ([]Tag, error) {
 	reader, err := repo.SQLClient.Query(
 		`SELECT tags.tag_id, tags.text
@@ -111,3 +148,7 @@ func (repo *TagsSQLRepository) CreateTag(tag string) (utils.UID, error) {
 func (repo *TagsSQLRepository) AddTagToTask(taskID utils.UID, tagID utils.UID) error {
 	return repo.SQLClient.Exec("INSERT INTO task_tag(task_id, tag_id) VALUES ($1, $2)", taskID, tagID)
 }
+
+func (repo *TagsSQLRepository) RemoveTagFromTask(taskID utils.UID, tagID utils.UID) error {
+	return repo.SQLClient.Exec("DELETE FROM task_tag WHERE task_id = $1 AND tag_id = $2", taskID, tagID)
+}
@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -15,38 +19,366 @@ const (
 	LIKED              = "LIKED"
 	RECOMMENDATIONS    = "RECOMMENDATIONS"
 	REPLIED            = "REPLIED"
+	SUBSCRIBED         = "SUBSCRIBED"
+	HOME               = "HOME"
+	TRENDING           = "TRENDING"
 )
 
+const (
+	ANY_TAGS = "ANY"
+	ALL_TAGS = "ALL"
+)
+
+const (
+	NEWEST_FIRST       = "NEWEST"
+	OLDEST_FIRST       = "OLDEST"
+	RECENTLY_PUBLISHED = "PUBLISHED"
+	MOST_REPLIES       = "REPLIES"
+	MOST_LIKES         = "LIKES"
+	RELEVANCE          = "RELEVANCE"
+)
+
+func IsFeedSort(sort string) bool {
+	switch sort {
+	case NEWEST_FIRST, OLDEST_FIRST, RECENTLY_PUBLISHED, MOST_REPLIES, MOST_LIKES, RELEVANCE:
+		return true
+	}
+	return false
+}
+
+const (
+	OPEN_TASKS   = "OPEN"
+	CLOSED_TASKS = "CLOSED"
+	DRAFT_TASKS  = "DRAFT"
+)
+
+const (
+	TASK_DRAFT     = "DRAFT"
+	TASK_OPEN      = "OPEN"
+	TASK_ASSIGNED  = "ASSIGNED"
+	TASK_COMPLETED = "COMPLETED"
+	TASK_CANCELLED = "CANCELLED"
+	TASK_EXPIRED   = "EXPIRED"
+)
+
+// ways a task state can be changed, each one has its own endpoint or job
+const (
+	STATE_TRANSITION   = "STATE"
+	ASSIGN_TRANSITION  = "ASSIGN"
+	PUBLISH_TRANSITION = "PUBLISH"
+	RENEW_TRANSITION   = "RENEW"
+	EXPIRY_TRANSITION  = "EXPIRY"
+)
+
+// allowed task state changes and the way each of them is made, every transition must be listed here
+var taskTransitions = map[string]map[string]string{
+	TASK_DRAFT:     {TASK_OPEN: PUBLISH_TRANSITION},
+	TASK_OPEN:      {TASK_ASSIGNED: ASSIGN_TRANSITION, TASK_CANCELLED: STATE_TRANSITION, TASK_EXPIRED: EXPIRY_TRANSITION},
+	TASK_ASSIGNED:  {TASK_COMPLETED: STATE_TRANSITION, TASK_CANCELLED: STATE_TRANSITION, TASK_OPEN: STATE_TRANSITION},
+	TASK_CANCELLED: {TASK_OPEN: STATE_TRANSITION},
+	TASK_EXPIRED:   {TASK_OPEN: RENEW_TRANSITION},
+}
+
+func CanTransitionTask(from string, to string, via string) bool {
+	transition, ok := taskTransitions[from][to]
+	return ok && transition == via
+}
+
+const (
+	TRENDING_WINDOW_HOURS    = 72
+	TRENDING_HALF_LIFE_HOURS = 24
+)
+
+func IsFeedScope(scope string) bool {
+	switch scope {
+	case NOT_ASSIGNED_TASKS, CUSTOMER_TASKS, DOER_TASKS, LIKED, RECOMMENDATIONS, REPLIED, SUBSCRIBED, HOME, TRENDING:
+		return true
+	}
+	return false
+}
+
+// amounts are in whole units of the currency
+type Budget struct {
+	Min      int32  `json:"min"`
+	Max      int32  `json:"max"`
+	Currency string `json:"currency"`
+}
+
+type Location struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	Place     string  `json:"place,omitempty"`
+}
+
 type Task struct {
 	ID           utils.UID        `json:"id"`
 	Name         string           `json:"name"`
 	Description  string           `json:"description,omitempty"`
 	Customer     UserData         `json:"customer"`
+	State        string           `json:"state"`
 	Closed       bool             `json:"closed"`
 	Owns         bool             `json:"owns"`
 	Liked        bool             `json:"liked"`
 	RepliesCount int32            `json:"replies"`
+	LikesCount   int32            `json:"likesCount"`
 	Tags         utils.JSONObject `json:"tags"`
+	Budget       *Budget          `json:"budget,omitempty"`
+	Deadline     *time.Time       `json:"deadline,omitempty"`
+	Location     *Location        `json:"location,omitempty"`
+	PublishAt    *time.Time       `json:"publishAt,omitempty"`
 	CreatedAt    time.Time        `json:"createdAt"`
+	UpdatedAt    time.Time        `json:"updatedAt"`
+	Version      int32            `json:"-"`
+	Highlight    string           `json:"highlight,omitempty"`
+	Source       string           `json:"source,omitempty"`
+	Cursor       *utils.Cursor    `json:"-"`
+}
+
+type TaskStateChange struct {
+	User      UserData  `json:"user"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TaskLike struct {
+	User      UserData      `json:"user"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Cursor    *utils.Cursor `json:"-"`
+}
+
+// Similarity is trigram similarity of names and descriptions joined together
+type SimilarTask struct {
+	ID         utils.UID
+	Similarity float32
+	Tags       []string
+}
+
+// task left out of recommendation candidates by GetTasksTags
+type ExcludedTask struct {
+	TaskID  utils.UID
+	State   string
+	Deleted bool
+	Liked   bool
+	Tagged  bool
 }
 
 type TasksRepository interface {
-	GetTasksFeed(scope string, request string, userID utils.UID) ([]Task, error)
-	GetTasksTags(userID utils.UID) ([]TaskTagLink, error)
+	GetTasksFeed(request FeedRequest, userID utils.UID) ([]Task, *utils.Cursor, error)
+	GetTasksTags(userID utils.UID, publishedBefore time.Time) ([]TaskTagLink, error)
+	GetExcludedTasks(userID utils.UID, limit int) ([]ExcludedTask, error)
 	GetTask(userID utils.UID, taskID utils.UID) (*Task, error)
 	GetTasks(userID utils.UID, tasksID []utils.UID) ([]Task, error)
+	ExportTasks(filters FeedFilters, fn func(task Task, tags []string) error) error
+	FilterTasks(tasksID []utils.UID, filters FeedFilters) ([]utils.UID, error)
 	GetTaskCustomer(taskID utils.UID) (utils.UID, error)
-	SetTaskLike(userID utils.UID, taskID utils.UID, value bool) error
+	GetTaskDoer(taskID utils.UID) (utils.UID, error)
+	SetTaskLike(userID utils.UID, taskID utils.UID, value bool) (bool, error)
+	GetTaskLikes(taskID utils.UID, cursor *utils.Cursor, limit int) ([]TaskLike, *utils.Cursor, error)
 	CreateTask(task Task) (utils.UID, error)
-	CloseTask(taskID utils.UID, doerID utils.UID) error
+	UpdateTask(task Task, version int32) (bool, error)
+	GetTaskState(taskID utils.UID) (string, error)
+	TransitionTask(taskID utils.UID, userID utils.UID, from string, to string) (bool, error)
+	AssignTask(taskID utils.UID, userID utils.UID, doerID utils.UID) (bool, error)
+	GetTaskHistory(taskID utils.UID) ([]TaskStateChange, error)
+	PublishTask(taskID utils.UID, userID utils.UID) (bool, error)
+	PublishScheduledTasks() ([]utils.UID, error)
+	ExpireTasks(expiryDays int) error
+	FindSimilarTasks(customerID utils.UID, text string, windowDays int, allCustomers bool, threshold float32) ([]SimilarTask, error)
+	RenewTask(taskID utils.UID, userID utils.UID, deadline *time.Time) (bool, error)
+	DeleteTask(taskID utils.UID) (bool, error)
+	PurgeDeletedTasks(retentionHours int) error
+	WithTx(tx *db.SQLClient) TasksRepository
 }
 
 type TasksSQLRepository struct {
 	SQLClient *db.SQLClient
 }
 
-func (repo *TasksSQLRepository) buildTaskQuery(filter string) string {
-	return `SELECT tasks.task_id, tasks.name, tasks.description, tasks.doer_id IS NOT NULL AS closed, tasks.customer_id = $1 AS owns, likes.active IS NOT NULL AND likes.active AS liked, COUNT(DISTINCT replies.reply_id), JSON_AGG(DISTINCT JSONB_BUILD_OBJECT('id', ENCODE(tags.tag_id::text::bytea, 'base64'), 'text', tags.text)), users.user_id, users.name, tasks.created_at
+func (repo *TasksSQLRepository) WithTx(tx *db.SQLClient) TasksRepository {
+	return &TasksSQLRepository{SQLClient: tx}
+}
+
+// Radius is in kilometers and is applied only together with Near
+type FeedFilters struct {
+	Tags           []utils.UID
+	TagsMode       string
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	State          string
+	BudgetMin      *int32
+	BudgetMax      *int32
+	Currency       string
+	DeadlineWithin int
+	Near           *Location
+	Radius         float64
+}
+
+func (filters FeedFilters) IsEmpty() bool {
+	return len(filters.Tags) == 0 && filters.CreatedAfter == nil && filters.CreatedBefore == nil && filters.State == "" &&
+		filters.BudgetMin == nil && filters.BudgetMax == nil && filters.Currency == "" && filters.DeadlineWithin == 0 && filters.Near == nil
+}
+
+// At is the reference time for time-dependent scores, it's kept in cursors, so pages are consistent,
+// Exclude and ExcludeTrending keep the sources of the home feed disjoint
+type FeedRequest struct {
+	Scope           string
+	Query           string
+	Highlight       bool
+	Filters         FeedFilters
+	Sort            string
+	Cursor          *utils.Cursor
+	Limit           int
+	At              time.Time
+	Exclude         []utils.UID
+	ExcludeTrending bool
+}
+
+// collects filters and their arguments for buildTaskQuery,
+// $1 is always reserved for the current user
+type taskQuery struct {
+	filters   []string
+	score     string
+	ascending bool
+	highlight string
+	args      []interface{}
+	limit     int
+}
+
+const kilometersPerDegree = 111.045
+
+const taskDetailsColumns = "tasks.budget_min, tasks.budget_max, tasks.budget_currency, tasks.deadline, tasks.latitude, tasks.longitude, tasks.place, tasks.publish_at"
+
+// scan targets for nullable columns listed in taskDetailsColumns
+type taskDetails struct {
+	budgetMin, budgetMax sql.NullInt32
+	currency, place      sql.NullString
+	deadline, publishAt  sql.NullTime
+	latitude, longitude  sql.NullFloat64
+}
+
+func (details *taskDetails) targets() []interface{} {
+	return []interface{}{&details.budgetMin, &details.budgetMax, &details.currency, &details.deadline, &details.latitude, &details.longitude, &details.place, &details.publishAt}
+}
+
+func (details *taskDetails) apply(task *Task) {
+	task.Budget = nil
+	if details.currency.Valid {
+		task.Budget = &Budget{Min: details.budgetMin.Int32, Max: details.budgetMax.Int32, Currency: details.currency.String}
+	}
+
+	task.Deadline = nil
+	if details.deadline.Valid {
+		value := details.deadline.Time
+		task.Deadline = &value
+	}
+
+	task.Location = nil
+	if details.latitude.Valid && details.longitude.Valid {
+		task.Location = &Location{Latitude: details.latitude.Float64, Longitude: details.longitude.Float64, Place: details.place.String}
+	}
+
+	task.PublishAt = nil
+	if details.publishAt.Valid {
+		value := details.publishAt.Time
+		task.PublishAt = &value
+	}
+}
+
+const (
+	noTaskScore      = "0::float8"
+	repliesTaskScore = "(SELECT COUNT(*) FROM replies AS sort_replies WHERE sort_replies.task_id = tasks.task_id)::float8"
+	likesTaskScore   = "tasks.likes_count::float8"
+	// drafts aren't published yet, so they are ordered by creation time
+	publishedTaskScore = "EXTRACT(EPOCH FROM COALESCE(tasks.published_at, tasks.created_at))::float8"
+)
+
+// drafts are visible only to their customers
+func makeTaskQuery(userID utils.UID) *taskQuery {
+	return &taskQuery{
+		filters:   []string{"tasks.deleted_at IS NULL", "(tasks.state <> '" + TASK_DRAFT + "' OR tasks.customer_id = $1)"},
+		score:     noTaskScore,
+		highlight: "''",
+		args:      []interface{}{userID},
+	}
+}
+
+func (query *taskQuery) addArg(value interface{}) string {
+	query.args = append(query.args, value)
+	return "$" + strconv.Itoa(len(query.args))
+}
+
+func (query *taskQuery) addFilter(filter string) {
+	query.filters = append(query.filters, filter)
+}
+
+func (query *taskQuery) addFeedFilters(filters FeedFilters) {
+	if len(filters.Tags) > 0 {
+		tags := query.addArg(pq.Array(filters.Tags)) + "::bigint[]"
+		if filters.TagsMode == ALL_TAGS {
+			query.addFilter(tags + " <@ ARRAY(SELECT filter_tag.tag_id FROM task_tag AS filter_tag WHERE filter_tag.task_id = tasks.task_id)")
+		} else {
+			query.addFilter(tags + " && ARRAY(SELECT filter_tag.tag_id FROM task_tag AS filter_tag WHERE filter_tag.task_id = tasks.task_id)")
+		}
+	}
+
+	if filters.CreatedAfter != nil {
+		query.addFilter("tasks.created_at >= " + query.addArg(*filters.CreatedAfter))
+	}
+
+	if filters.CreatedBefore != nil {
+		query.addFilter("tasks.created_at < " + query.addArg(*filters.CreatedBefore))
+	}
+
+	switch filters.State {
+	case OPEN_TASKS:
+		query.addFilter("tasks.state = '" + TASK_OPEN + "'")
+	case CLOSED_TASKS:
+		query.addFilter("tasks.state NOT IN ('" + TASK_OPEN + "', '" + TASK_DRAFT + "')")
+	case DRAFT_TASKS:
+		query.addFilter("tasks.state = '" + TASK_DRAFT + "'")
+	}
+
+	if filters.Currency != "" {
+		query.addFilter("tasks.budget_currency = " + query.addArg(filters.Currency))
+	}
+
+	// budget ranges should overlap with the requested one
+	if filters.BudgetMin != nil {
+		query.addFilter("tasks.budget_max >= " + query.addArg(*filters.BudgetMin))
+	}
+
+	if filters.BudgetMax != nil {
+		query.addFilter("tasks.budget_min <= " + query.addArg(*filters.BudgetMax))
+	}
+
+	if filters.DeadlineWithin > 0 {
+		query.addFilter("tasks.deadline BETWEEN now() AND now() + make_interval(days => " + query.addArg(filters.DeadlineWithin) + ")")
+	}
+
+	if filters.Near != nil {
+		// latitude range is checked first, so the index can be used before computing the distance
+		latitudeDelta := filters.Radius / kilometersPerDegree
+		query.addFilter("tasks.latitude BETWEEN " + query.addArg(filters.Near.Latitude-latitudeDelta) + " AND " + query.addArg(filters.Near.Latitude+latitudeDelta))
+		query.addFilter("haversine_distance(tasks.latitude, tasks.longitude, " + query.addArg(filters.Near.Latitude) + ", " + query.addArg(filters.Near.Longitude) + ") <= " + query.addArg(filters.Radius))
+	}
+}
+
+func (query *taskQuery) addCursor(cursor *utils.Cursor) {
+	if cursor == nil {
+		return
+	}
+
+	operator := " < "
+	if query.ascending {
+		operator = " > "
+	}
+
+	query.addFilter("(" + query.score + ", tasks.task_id)" + operator + "(" + query.addArg(cursor.Score) + ", " + query.addArg(cursor.ID) + ")")
+}
+
+func (repo *TasksSQLRepository) buildTaskQuery(query *taskQuery) string {
+	result := `SELECT tasks.task_id, tasks.name, tasks.description, ` + taskDetailsColumns + `, tasks.state, tasks.state NOT IN ('OPEN', 'DRAFT') AS closed, tasks.customer_id = $1 AS owns, likes.active IS NOT NULL AND likes.active AS liked, COUNT(DISTINCT replies.reply_id), tasks.likes_count, JSON_AGG(DISTINCT JSONB_BUILD_OBJECT('id', ENCODE(tags.tag_id::text::bytea, 'base64'), 'text', tags.text)), users.user_id, users.name, tasks.created_at, tasks.updated_at, tasks.version, ` + query.highlight + `, ` + query.score + `
 		FROM tasks 
 		JOIN users 
 		ON tasks.customer_id = users.user_id
@@ -58,28 +390,29 @@ func (repo *TasksSQLRepository) buildTaskQuery(filter string) string {
 		LEFT JOIN task_tag
 		ON task_tag.task_id = tasks.task_id
 		LEFT JOIN tags
-		ON task_tag.tag_id = tags.tag_id ` +
-		filter +
-		` GROUP BY tasks.task_id, users.user_id, likes.like_id
-		ORDER BY tasks.task_id DESC`
-}
+		ON task_tag.tag_id = tags.tag_id`
 
-func (repo *TasksSQLRepository) getTasksFeedReader(scope string, request string, userID utils.UID) (db.ResponseReader, error) {
-	switch scope {
-	case CUSTOMER_TASKS:
-		return repo.SQLClient.Query(repo.buildTaskQuery("WHERE tasks.customer_id = $1 AND tasks.name LIKE '%' || $2 || '%'"), userID, request)
-	case DOER_TASKS:
-		return repo.SQLClient.Query(repo.buildTaskQuery("WHERE tasks.doer_id = $1 AND tasks.name LIKE '%' || $2 || '%'"), userID, request)
-	case LIKED:
-		return repo.SQLClient.Query(repo.buildTaskQuery("WHERE likes.active IS NOT NULL AND likes.active AND tasks.name LIKE '%' || $2 || '%'"), userID, request)
-	case REPLIED:
-		return repo.SQLClient.Query(repo.buildTaskQuery("WHERE replies.creator_id = $1 AND tasks.name LIKE '%' || $2 || '%'"), userID, request)
+	if len(query.filters) > 0 {
+		result += " WHERE " + strings.Join(query.filters, " AND ")
+	}
+
+	direction := " DESC"
+	if query.ascending {
+		direction = " ASC"
+	}
+
+	result += ` GROUP BY tasks.task_id, users.user_id, likes.like_id
+		ORDER BY ` + query.score + direction + `, tasks.task_id` + direction
+
+	if query.limit > 0 {
+		result += " LIMIT " + strconv.Itoa(query.limit)
 	}
-	return repo.SQLClient.Query(repo.buildTaskQuery("WHERE tasks.doer_id IS NULL AND tasks.name LIKE '%' || $2 || '%'"), userID, request)
+
+	return result
 }
 
-func (repo *TasksSQLRepository) GetTasksFeed(scope string, request string, userID utils.UID) ([]Task, error) {
-	reader, err := repo.getTasksFeedReader(scope, request, userID)
+func (repo *TasksSQLRepository) readTasks(query *taskQuery) ([]Task, error) {
+	reader, err := repo.SQLClient.Query(repo.buildTaskQuery(query), query.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -87,9 +420,14 @@ func (repo *TasksSQLRepository) GetTasksFeed(scope string, request string, userI
 
 	result := []Task{}
 	row := Task{}
+	score := float64(0)
+	details := taskDetails{}
+
+	dest := append([]interface{}{&row.ID, &row.Name, &row.Description}, details.targets()...)
+	dest = append(dest, &row.State, &row.Closed, &row.Owns, &row.Liked, &row.RepliesCount, &row.LikesCount, &row.Tags, &row.Customer.ID, &row.Customer.Name, &row.CreatedAt, &row.UpdatedAt, &row.Version, &row.Highlight, &score)
 
 	for {
-		ok, err := reader.NextRow(&row.ID, &row.Name, &row.Description, &row.Closed, &row.Owns, &row.Liked, &row.RepliesCount, &row.Tags, &row.Customer.ID, &row.Customer.Name, &row.CreatedAt)
+		ok, err := reader.NextRow(dest...)
 		if err != nil {
 			return nil, err
 		}
@@ -97,40 +435,192 @@ func (repo *TasksSQLRepository) GetTasksFeed(scope string, request string, userI
 			break
 		}
 
+		details.apply(&row)
+
+		row.Cursor = &utils.Cursor{ID: row.ID, Score: score}
 		result = append(result, row)
 	}
 
 	return result, nil
 }
 
-func (repo *TasksSQLRepository) GetTask(userID utils.UID, taskID utils.UID) (*Task, error) {
-	reader, err := repo.SQLClient.Query(repo.buildTaskQuery("WHERE tasks.task_id = $2"), userID, taskID)
+func (query *taskQuery) trendingScore(at time.Time) string {
+	return "task_trending_score(tasks.task_id, tasks.published_at, " + query.addArg(TRENDING_WINDOW_HOURS) + ", " + query.addArg(TRENDING_HALF_LIFE_HOURS) + ", " + query.addArg(at) + "::timestamptz::timestamp)"
+}
+
+func (repo *TasksSQLRepository) buildTasksFeedQuery(request FeedRequest, userID utils.UID) *taskQuery {
+	query := makeTaskQuery(userID)
+
+	at := request.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	switch request.Scope {
+	case CUSTOMER_TASKS:
+		query.addFilter("tasks.customer_id = $1")
+	case DOER_TASKS:
+		query.addFilter("tasks.doer_id = $1")
+	case LIKED:
+		query.addFilter("likes.active IS NOT NULL AND likes.active")
+	case REPLIED:
+		query.addFilter("replies.creator_id = $1")
+	case TRENDING:
+		query.score = query.trendingScore(at)
+		query.addFilter("tasks.state = '" + TASK_OPEN + "'")
+		query.addFilter("tasks.published_at <= " + query.addArg(at) + "::timestamptz")
+		query.addFilter(query.score + " > 0")
+	case SUBSCRIBED:
+		query.addFilter("tasks.state = '" + TASK_OPEN + "' AND tasks.customer_id <> $1")
+		query.addFilter(subscribedTaskFilter)
+	default:
+		query.addFilter("tasks.state = '" + TASK_OPEN + "'")
+	}
+
+	rank := ""
+	if request.Query != "" {
+		tsQuery := "websearch_to_tsquery('simple', " + query.addArg(request.Query) + ")"
+		query.addFilter("tasks.search_vector @@ " + tsQuery)
+		rank = "ts_rank(tasks.search_vector, " + tsQuery + ")::float8"
+
+		if request.Highlight {
+			query.highlight = "ts_headline('simple', tasks.description, " + tsQuery + ", 'MaxFragments=2')"
+		}
+	}
+
+	switch request.Sort {
+	case NEWEST_FIRST:
+		query.score = noTaskScore
+	case OLDEST_FIRST:
+		query.score = noTaskScore
+		query.ascending = true
+	case RECENTLY_PUBLISHED:
+		query.score = publishedTaskScore
+	case MOST_REPLIES:
+		query.score = repliesTaskScore
+	case MOST_LIKES:
+		query.score = likesTaskScore
+	case RELEVANCE:
+		query.score = rank
+	default:
+		if rank != "" && query.score == noTaskScore {
+			query.score = rank
+		}
+	}
+
+	if len(request.Exclude) > 0 {
+		query.addFilter("tasks.task_id <> ALL(" + query.addArg(pq.Array(request.Exclude)) + "::bigint[])")
+	}
+
+	if request.ExcludeTrending {
+		query.addFilter(query.trendingScore(at) + " <= 0")
+	}
+
+	query.addFeedFilters(request.Filters)
+	query.addCursor(request.Cursor)
+	query.limit = request.Limit + 1
+
+	return query
+}
+
+func (repo *TasksSQLRepository) GetTasksFeed(request FeedRequest, userID utils.UID) ([]Task, *utils.Cursor, error) {
+	tasks, err := repo.readTasks(repo.buildTasksFeedQuery(request, userID))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer reader.Close()
 
-	result := Task{}
-	err = reader.GetRow(&result.ID, &result.Name, &result.Description, &result.Closed, &result.Owns, &result.Liked, &result.RepliesCount, &result.Tags, &result.Customer.ID, &result.Customer.Name, &result.CreatedAt)
+	if len(tasks) <= request.Limit {
+		return tasks, nil, nil
+	}
+
+	tasks = tasks[:request.Limit]
+	return tasks, tasks[len(tasks)-1].Cursor, nil
+}
+
+func (repo *TasksSQLRepository) GetTask(userID utils.UID, taskID utils.UID) (*Task, error) {
+	query := makeTaskQuery(userID)
+	query.addFilter("tasks.task_id = " + query.addArg(taskID))
+
+	tasks, err := repo.readTasks(query)
 	if err != nil {
 		return nil, err
 	}
 
-	return &result, nil
+	if len(tasks) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &tasks[0], nil
 }
 
 func (repo *TasksSQLRepository) GetTasks(userID utils.UID, tasksID []utils.UID) ([]Task, error) {
-	reader, err := repo.SQLClient.Query(repo.buildTaskQuery("WHERE tasks.task_id = ANY($2)"), userID, pq.Array(tasksID))
+	query := makeTaskQuery(userID)
+	query.addFilter("tasks.task_id = ANY(" + query.addArg(pq.Array(tasksID)) + ")")
+
+	return repo.readTasks(query)
+}
+
+// streams tasks with their tags texts to fn without loading all of them into memory,
+// iteration stops on the first error returned by fn
+func (repo *TasksSQLRepository) ExportTasks(filters FeedFilters, fn func(task Task, tags []string) error) error {
+	query := &taskQuery{}
+	query.addFilter("tasks.deleted_at IS NULL")
+	query.addFeedFilters(filters)
+
+	reader, err := repo.SQLClient.Query(
+		`SELECT tasks.task_id, tasks.name, tasks.description, `+taskDetailsColumns+`, tasks.state, tasks.customer_id, tasks.created_at,
+		ARRAY(SELECT tags.text FROM task_tag JOIN tags ON task_tag.tag_id = tags.tag_id WHERE task_tag.task_id = tasks.task_id ORDER BY tags.text)
+		FROM tasks
+		WHERE `+strings.Join(query.filters, " AND ")+`
+		ORDER BY tasks.task_id`, query.args...,
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer reader.Close()
 
-	result := []Task{}
 	row := Task{}
+	tags := []string{}
+	details := taskDetails{}
+
+	dest := append([]interface{}{&row.ID, &row.Name, &row.Description}, details.targets()...)
+	dest = append(dest, &row.State, &row.Customer.ID, &row.CreatedAt, pq.Array(&tags))
 
 	for {
-		ok, err := reader.NextRow(&row.ID, &row.Name, &row.Description, &row.Closed, &row.Owns, &row.Liked, &row.RepliesCount, &row.Tags, &row.Customer.ID, &row.Customer.Name, &row.CreatedAt)
+		ok, err := reader.NextRow(dest...)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		details.apply(&row)
+		err = fn(row, tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *TasksSQLRepository) FilterTasks(tasksID []utils.UID, filters FeedFilters) ([]utils.UID, error) {
+	query := &taskQuery{}
+	query.addFilter("tasks.deleted_at IS NULL")
+	query.addFilter("tasks.task_id = ANY(" + query.addArg(pq.Array(tasksID)) + ")")
+	query.addFeedFilters(filters)
+
+	reader, err := repo.SQLClient.Query("SELECT tasks.task_id FROM tasks WHERE "+strings.Join(query.filters, " AND "), query.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := []utils.UID{}
+	row := utils.UID(0)
+	for {
+		ok, err := reader.NextRow(&row)
 		if err != nil {
 			return nil, err
 		}
@@ -145,7 +635,7 @@ func (repo *TasksSQLRepository) GetTasks(userID utils.UID, tasksID []utils.UID)
 }
 
 func (repo *TasksSQLRepository) GetTaskCustomer(taskID utils.UID) (utils.UID, error) {
-	reader, err := repo.SQLClient.Query("SELECT tasks.customer_id FROM tasks WHERE tasks.task_id = $1", taskID)
+	reader, err := repo.SQLClient.Query("SELECT tasks.customer_id FROM tasks WHERE tasks.task_id = $1 AND tasks.deleted_at IS NULL", taskID)
 	if err != nil {
 		return 0, err
 	}
@@ -160,15 +650,134 @@ func (repo *TasksSQLRepository) GetTaskCustomer(taskID utils.UID) (utils.UID, er
 	return row, nil
 }
 
-func (repo *TasksSQLRepository) SetTaskLike(userID utils.UID, taskID utils.UID, value bool) error {
-	return repo.SQLClient.Exec(
-		`INSERT INTO likes(user_id, task_id, active) VALUES ($1, $2, $3)
-		ON CONFLICT ON CONSTRAINT likes_user_task DO UPDATE SET active = $3`, userID, taskID, value,
+// zero is returned if the task has no doer
+func (repo *TasksSQLRepository) GetTaskDoer(taskID utils.UID) (utils.UID, error) {
+	reader, err := repo.SQLClient.Query("SELECT COALESCE(tasks.doer_id, 0) FROM tasks WHERE tasks.task_id = $1 AND tasks.deleted_at IS NULL", taskID)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	row := utils.UID(0)
+	err = reader.GetRow(&row)
+	if err != nil {
+		return 0, err
+	}
+
+	return row, nil
+}
+
+// likes_count is changed only if the like was actually toggled,
+// conflicting row is locked by the upsert, so concurrent toggles are counted correctly,
+// created_at is moved on activation, so the like is counted in engagement bucket of that time,
+// returns false if the task doesn't exist or isn't visible to the user
+func (repo *TasksSQLRepository) SetTaskLike(userID utils.UID, taskID utils.UID, value bool) (bool, error) {
+	reader, err := repo.SQLClient.Query(
+		`WITH target AS (
+			SELECT tasks.task_id FROM tasks WHERE tasks.task_id = $2 AND tasks.deleted_at IS NULL AND (tasks.state <> 'DRAFT' OR tasks.customer_id = $1)
+		), changed AS (
+			INSERT INTO likes(user_id, task_id, active) 
+			SELECT $1, target.task_id, $3 FROM target
+			ON CONFLICT ON CONSTRAINT likes_user_task DO UPDATE SET active = $3, created_at = CASE WHEN $3 THEN now() ELSE likes.created_at END
+			WHERE likes.active <> $3
+			RETURNING likes.task_id, likes.xmax = 0 AS inserted
+		), counted AS (
+			UPDATE tasks SET likes_count = tasks.likes_count + (CASE WHEN $3 THEN 1 WHEN changed.inserted THEN 0 ELSE -1 END)
+			FROM changed
+			WHERE tasks.task_id = changed.task_id
+		)
+		SELECT task_id FROM target`, userID, taskID, value,
 	)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	row := utils.UID(0)
+	return reader.NextRow(&row)
 }
 
+func (repo *TasksSQLRepository) GetTaskLikes(taskID utils.UID, cursor *utils.Cursor, limit int) ([]TaskLike, *utils.Cursor, error) {
+	after := utils.UID(0)
+	if cursor != nil {
+		after = cursor.ID
+	}
+
+	reader, err := repo.SQLClient.Query(
+		`SELECT likes.like_id, users.user_id, users.name, likes.created_at
+		FROM likes JOIN users
+		ON likes.user_id = users.user_id
+		WHERE likes.task_id = $1 AND likes.active
+		AND ($2::bigint = 0 OR likes.like_id < $2)
+		ORDER BY likes.like_id DESC
+		LIMIT $3`, taskID, after, limit+1,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	result := []TaskLike{}
+	row := TaskLike{}
+	likeID := utils.UID(0)
+	for {
+		ok, err := reader.NextRow(&likeID, &row.User.ID, &row.User.Name, &row.CreatedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			break
+		}
+
+		row.Cursor = &utils.Cursor{ID: likeID}
+		result = append(result, row)
+	}
+
+	if len(result) <= limit {
+		return result, nil, nil
+	}
+
+	result = result[:limit]
+	return result, result[len(result)-1].Cursor, nil
+}
+
+// returns optional task fields in the order of budget_min, budget_max, budget_currency, deadline, latitude, longitude, place
+func taskDetailsArgs(task Task) []interface{} {
+	args := []interface{}{nil, nil, nil, task.Deadline, nil, nil, nil}
+	if task.Budget != nil {
+		args[0], args[1], args[2] = task.Budget.Min, task.Budget.Max, task.Budget.Currency
+	}
+
+	if task.Location != nil {
+		args[4], args[5] = task.Location.Latitude, task.Location.Longitude
+		if task.Location.Place != "" {
+			args[6] = task.Location.Place
+		}
+	}
+
+	return args
+}
+
+// creation time is set to now() unless task.CreatedAt is provided, which is used by imports,
+// task is created open unless task.State is set to a state that doesn't need a doer
 func (repo *TasksSQLRepository) CreateTask(task Task) (utils.UID, error) {
-	reader, err := repo.SQLClient.Query("INSERT INTO tasks(name, description, customer_id) VALUES ($1, $2, $3) RETURNING task_id", task.Name, task.Description, task.Customer.ID)
+	var createdAt *time.Time
+	if !task.CreatedAt.IsZero() {
+		createdAt = &task.CreatedAt
+	}
+
+	state := TASK_OPEN
+	switch task.State {
+	case TASK_DRAFT, TASK_COMPLETED, TASK_CANCELLED, TASK_EXPIRED:
+		state = task.State
+	}
+
+	args := append([]interface{}{task.Name, task.Description, task.Customer.ID}, taskDetailsArgs(task)...)
+	args = append(args, createdAt, state, task.PublishAt)
+	reader, err := repo.SQLClient.Query(
+		`INSERT INTO tasks(name, description, customer_id, budget_min, budget_max, budget_currency, deadline, latitude, longitude, place, created_at, updated_at, state, publish_at, published_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE($11::timestamp, now()), COALESCE($11::timestamp, now()), $12::text, $13, CASE WHEN $12::text = 'DRAFT' THEN NULL ELSE COALESCE($11::timestamp, now()) END) RETURNING task_id`, args...,
+	)
 	if err != nil {
 		return 0, err
 	}
@@ -183,18 +792,359 @@ func (repo *TasksSQLRepository) CreateTask(task Task) (utils.UID, error) {
 	return row, nil
 }
 
-func (repo *TasksSQLRepository) CloseTask(taskID utils.UID, doerID utils.UID) error {
-	return repo.SQLClient.Exec("UPDATE tasks SET doer_id = $2 WHERE task_id = $1", taskID, doerID)
+// publish time can be changed only while the task is a draft
+func (repo *TasksSQLRepository) UpdateTask(task Task, version int32) (bool, error) {
+	args := append([]interface{}{task.ID, task.Name, task.Description, version}, taskDetailsArgs(task)...)
+	args = append(args, task.PublishAt)
+	reader, err := repo.SQLClient.Query(
+		`UPDATE tasks SET name = $2, description = $3, 
+		budget_min = $5, budget_max = $6, budget_currency = $7, deadline = $8, latitude = $9, longitude = $10, place = $11,
+		publish_at = CASE WHEN state = 'DRAFT' THEN $12::timestamp ELSE publish_at END,
+		updated_at = now(), version = version + 1
+		WHERE task_id = $1 AND version = $4 AND deleted_at IS NULL
+		RETURNING version`, args...,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	row := int32(0)
+	return reader.NextRow(&row)
+}
+
+func (repo *TasksSQLRepository) GetTaskState(taskID utils.UID) (string, error) {
+	reader, err := repo.SQLClient.Query("SELECT tasks.state FROM tasks WHERE tasks.task_id = $1 AND tasks.deleted_at IS NULL", taskID)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	row := ""
+	err = reader.GetRow(&row)
+	if err != nil {
+		return "", err
+	}
+
+	return row, nil
+}
+
+// state change applied by transitionTasks, caller arguments of set and filter start from $4
+type taskStateChange struct {
+	from   string
+	to     string
+	via    string
+	set    string
+	filter string
+	// column of the task with the user to notify, nobody is notified if empty
+	notify       string
+	notification int
+}
+
+// the only path that changes task state: the change has to be listed in taskTransitions,
+// tasks are updated only if they're still in the expected state and every change is recorded
+func (repo *TasksSQLRepository) transitionTasks(userID utils.UID, change taskStateChange, args ...interface{}) ([]utils.UID, error) {
+	if !CanTransitionTask(change.from, change.to, change.via) {
+		return []utils.UID{}, nil
+	}
+
+	query := `WITH updated AS (
+			UPDATE tasks SET state = $3, updated_at = now(), version = version + 1` + change.set + `
+			WHERE state = $2 AND deleted_at IS NULL AND ` + change.filter + `
+			RETURNING task_id, customer_id, doer_id
+		), history AS (
+			INSERT INTO task_state_history(task_id, user_id, from_state, to_state)
+			SELECT updated.task_id, NULLIF($1::bigint, 0), $2, $3 FROM updated
+		)`
+	if change.notify != "" {
+		query += `, notified AS (
+			INSERT INTO notifications(user_id, type, trigger_id)
+			SELECT updated.` + change.notify + `, ` + strconv.Itoa(change.notification) + `, updated.task_id FROM updated
+		)`
+	}
+	query += `
+		SELECT task_id FROM updated`
+
+	args = append([]interface{}{userID, change.from, change.to}, args...)
+	reader, err := repo.SQLClient.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := []utils.UID{}
+	row := utils.UID(0)
+	for {
+		ok, err := reader.NextRow(&row)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		result = append(result, row)
+	}
+
+	return result, nil
+}
+
+func (repo *TasksSQLRepository) transitionTask(taskID utils.UID, userID utils.UID, change taskStateChange, args ...interface{}) (bool, error) {
+	change.filter = "task_id = $4 AND " + change.filter
+	changed, err := repo.transitionTasks(userID, change, append([]interface{}{taskID}, args...)...)
+	if err != nil {
+		return false, err
+	}
+
+	return len(changed) > 0, nil
+}
+
+// changes task state through the generic endpoint, doer is cleared and expiry period is restarted on reopen
+func (repo *TasksSQLRepository) TransitionTask(taskID utils.UID, userID utils.UID, from string, to string) (bool, error) {
+	change := taskStateChange{from: from, to: to, via: STATE_TRANSITION, filter: "true"}
+	if to == TASK_OPEN {
+		change.set = ", doer_id = NULL, opened_at = now()"
+	}
+
+	return repo.transitionTask(taskID, userID, change)
+}
+
+// assigns open task to a user with a visible reply to it, records the change
+// and notifies the doer in a single statement, so either all of it is applied or nothing
+func (repo *TasksSQLRepository) AssignTask(taskID utils.UID, userID utils.UID, doerID utils.UID) (bool, error) {
+	return repo.transitionTask(taskID, userID, taskStateChange{
+		from: TASK_OPEN,
+		to:   TASK_ASSIGNED,
+		via:  ASSIGN_TRANSITION,
+		set:  ", doer_id = $5",
+		filter: `EXISTS (
+				SELECT 1 FROM replies 
+				WHERE replies.task_id = tasks.task_id
+				AND replies.creator_id = $5
+				AND replies.hidden = false
+			)`,
+		notify:       "doer_id",
+		notification: TASK_CLOSE_NOTIFICATION,
+	}, doerID)
+}
+
+// opens drafts matching the filter, creation time is kept,
+// published tasks appear in the fresh feeds by their publish time
+func (repo *TasksSQLRepository) publishTasks(userID utils.UID, filter string, args ...interface{}) ([]utils.UID, error) {
+	return repo.transitionTasks(userID, taskStateChange{
+		from:   TASK_DRAFT,
+		to:     TASK_OPEN,
+		via:    PUBLISH_TRANSITION,
+		set:    ", publish_at = NULL, published_at = now(), opened_at = now()",
+		filter: filter,
+	}, args...)
+}
+
+func (repo *TasksSQLRepository) PublishTask(taskID utils.UID, userID utils.UID) (bool, error) {
+	published, err := repo.publishTasks(userID, "task_id = $4", taskID)
+	if err != nil {
+		return false, err
+	}
+
+	return len(published) > 0, nil
+}
+
+// publishes drafts with due publish time, returns IDs of published tasks
+func (repo *TasksSQLRepository) PublishScheduledTasks() ([]utils.UID, error) {
+	return repo.publishTasks(0, "publish_at <= now()")
+}
+
+// expires open tasks that passed their deadline or stayed open for expiryDays,
+// records the changes and notifies the customers in a single statement
+func (repo *TasksSQLRepository) ExpireTasks(expiryDays int) error {
+	_, err := repo.transitionTasks(0, taskStateChange{
+		from:         TASK_OPEN,
+		to:           TASK_EXPIRED,
+		via:          EXPIRY_TRANSITION,
+		filter:       "(deadline < now() OR opened_at < now() - make_interval(days => $4))",
+		notify:       "customer_id",
+		notification: TASK_EXPIRED_NOTIFICATION,
+	}, expiryDays)
+	return err
+}
+
+// reopens expired task and restarts its expiry period, deadline is replaced if provided,
+// task isn't renewed if the resulting deadline has already passed
+func (repo *TasksSQLRepository) RenewTask(taskID utils.UID, userID utils.UID, deadline *time.Time) (bool, error) {
+	return repo.transitionTask(taskID, userID, taskStateChange{
+		from:   TASK_EXPIRED,
+		to:     TASK_OPEN,
+		via:    RENEW_TRANSITION,
+		set:    ", deadline = COALESCE($5, deadline), opened_at = now()",
+		filter: "(COALESCE($5, deadline) IS NULL OR COALESCE($5, deadline) > now())",
+	}, deadline)
+}
+
+// looks for open tasks created by the customer within the window or, if allCustomers is set, by anyone,
+// text is compared with the name and the description joined by a space
+// similarity threshold is set for the transaction only, so the % operator can use the trigram index
+func (repo *TasksSQLRepository) FindSimilarTasks(customerID utils.UID, text string, windowDays int, allCustomers bool, threshold float32) ([]SimilarTask, error) {
+	result := []SimilarTask{}
+	err := repo.SQLClient.WithTx(context.Background(), func(tx *db.SQLClient) error {
+		err := tx.Exec("SELECT set_config('pg_trgm.similarity_threshold', $1::float4::text, true)", threshold)
+		if err != nil {
+			return err
+		}
+
+		reader, err := tx.Query(
+			`SELECT tasks.task_id, similarity(tasks.name || ' ' || tasks.description, $1)::float4 AS text_similarity,
+			ARRAY(SELECT tags.text FROM task_tag JOIN tags ON task_tag.tag_id = tags.tag_id WHERE task_tag.task_id = tasks.task_id)
+			FROM tasks
+			WHERE tasks.state = 'OPEN' AND tasks.deleted_at IS NULL
+			AND ($4::boolean OR (tasks.customer_id = $2 AND tasks.created_at > now() - make_interval(days => $3)))
+			AND (tasks.name || ' ' || tasks.description) % $1
+			ORDER BY text_similarity DESC, tasks.task_id DESC
+			LIMIT 20`, text, customerID, windowDays, allCustomers,
+		)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		row := SimilarTask{}
+		for {
+			tags := []string{}
+			ok, err := reader.NextRow(&row.ID, &row.Similarity, pq.Array(&tags))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+
+			row.Tags = tags
+			result = append(result, row)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *TasksSQLRepository) GetTaskHistory(taskID utils.UID) ([]TaskStateChange, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT COALESCE(users.user_id, 0), COALESCE(users.name, ''), task_state_history.from_state, task_state_history.to_state, task_state_history.created_at
+		FROM task_state_history 
+		LEFT JOIN users
+		ON task_state_history.user_id = users.user_id
+		WHERE task_state_history.task_id = $1
+		ORDER BY task_state_history.created_at, task_state_history.history_id`, taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := []TaskStateChange{}
+	row := TaskStateChange{}
+	for {
+		ok, err := reader.NextRow(&row.User.ID, &row.User.Name, &row.From, &row.To, &row.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		result = append(result, row)
+	}
+
+	return result, nil
+}
+
+// assigned and completed tasks can't be deleted, since someone is working or has worked on them,
+// cancelled tasks can be deleted even if they had a doer
+func (repo *TasksSQLRepository) DeleteTask(taskID utils.UID) (bool, error) {
+	reader, err := repo.SQLClient.Query(
+		`UPDATE tasks SET deleted_at = now(), updated_at = now(), version = version + 1
+		WHERE task_id = $1 AND state NOT IN ($2, $3) AND deleted_at IS NULL
+		RETURNING task_id`, taskID, TASK_ASSIGNED, TASK_COMPLETED,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	row := utils.UID(0)
+	return reader.NextRow(&row)
+}
+
+// removes tasks deleted before the retention window, replies and other task data are removed by cascade,
+// notifications have no foreign keys so they are removed explicitly
+func (repo *TasksSQLRepository) PurgeDeletedTasks(retentionHours int) error {
+	return repo.SQLClient.Exec(
+		`WITH purged AS (
+			DELETE FROM tasks 
+			WHERE deleted_at < now() - make_interval(hours => $1)
+			RETURNING task_id
+		)
+		DELETE FROM notifications
+		WHERE trigger_id IN (SELECT task_id FROM purged)
+		OR trigger_id IN (SELECT replies.reply_id FROM replies JOIN purged ON replies.task_id = purged.task_id)`, retentionHours,
+	)
+}
+
+// mirrors conditions of GetTasksTags
+func (repo *TasksSQLRepository) GetExcludedTasks(userID utils.UID, limit int) ([]ExcludedTask, error) {
+	reader, err := repo.SQLClient.Query(
+		`SELECT tasks.task_id, tasks.state, tasks.deleted_at IS NOT NULL, likes.like_id IS NOT NULL,
+		EXISTS (SELECT 1 FROM task_tag WHERE task_tag.task_id = tasks.task_id) AS tagged
+		FROM tasks
+		LEFT JOIN likes
+		ON likes.task_id = tasks.task_id
+		AND likes.user_id = $1 AND likes.active = true
+		WHERE tasks.deleted_at IS NOT NULL
+		OR tasks.state <> 'OPEN'
+		OR likes.like_id IS NOT NULL
+		OR NOT EXISTS (SELECT 1 FROM task_tag WHERE task_tag.task_id = tasks.task_id)
+		ORDER BY tasks.task_id DESC
+		LIMIT $2`, userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result := []ExcludedTask{}
+	row := ExcludedTask{}
+	for {
+		ok, err := reader.NextRow(&row.TaskID, &row.State, &row.Deleted, &row.Liked, &row.Tagged)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		result = append(result, row)
+	}
+
+	return result, nil
 }
 
-func (repo *TasksSQLRepository) GetTasksTags(userID utils.UID) ([]TaskTagLink, error) {
+// tasks published after publishedBefore are left out, so candidates don't change between pages
+func (repo *TasksSQLRepository) GetTasksTags(userID utils.UID, publishedBefore time.Time) ([]TaskTagLink, error) {
 	reader, err := repo.SQLClient.Query(
 		`SELECT task_tag.task_id, task_tag.tag_id 
 		FROM likes 
 		RIGHT JOIN task_tag 
 		ON likes.task_id = task_tag.task_id 
 		AND likes.user_id = $1 AND likes.active = true 
-		WHERE likes.user_id IS NULL`, userID,
+		JOIN tasks
+		ON tasks.task_id = task_tag.task_id
+		AND tasks.deleted_at IS NULL
+		AND tasks.state = 'OPEN'
+		AND tasks.published_at <= $2::timestamptz
+		WHERE likes.user_id IS NULL`, userID, publishedBefore,
 	)
 
 	if err != nil {
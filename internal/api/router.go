@@ -15,8 +15,9 @@ import (
 
 func addCORSHeaders(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, DELETE")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, If-Match, If-None-Match, Idempotency-Key")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, DELETE")
+	w.Header().Set("Access-Control-Expose-Headers", "ETag, Location, Idempotent-Replayed")
 }
 
 func HandleCORS(r *http.Request) utils.HandlerResponse {
@@ -44,13 +45,44 @@ func BaseMiddleware(inner utils.BaseHandler, name string) http.Handler {
 			log.Printf("%s error: %v", name, response.Err)
 		}
 
-		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		w.WriteHeader(response.Code)
-		err := json.NewEncoder(w).Encode(response.Response)
+		if response.Stream != nil {
+			for key, values := range response.Headers {
+				w.Header()[key] = values
+			}
+
+			w.WriteHeader(response.Code)
+			err := response.Stream(w)
+			if err != nil {
+				log.Printf("%s response streaming error: %v", name, err)
+			}
+			return
+		}
+
+		body, err := json.Marshal(response.Response)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			log.Printf("%s response encoding error: %v", name, err)
+			return
+		}
+		body = append(body, '\n')
+
+		for key, values := range response.Headers {
+			w.Header()[key] = values
+		}
+
+		// successful reads always get an etag, other responses only extend the one set by handler
+		if response.Code == http.StatusOK && (r.Method == "GET" || w.Header().Get("ETag") != "") {
+			w.Header().Set("ETag", utils.MakeContentETag(w.Header().Get("ETag"), body))
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		if r.Method == "GET" && response.Code == http.StatusOK && utils.IsNotModified(r, w.Header()) {
+			w.WriteHeader(http.StatusNotModified)
+			return
 		}
+
+		w.WriteHeader(response.Code)
+		w.Write(body)
 	})
 }
 
@@ -64,49 +96,57 @@ func MakeRouter() *mux.Router {
 				SQLClient: db.GetSQLClient(),
 			},
 		},
-		&controller.TasksController{
-			TasksRepo: &repository.TasksSQLRepository{
+		controller.NewTasksController(db.GetSQLClient()),
+		&controller.RepliesController{
+			SQLClient: db.GetSQLClient(),
+			RepliesRepo: &repository.RepliesSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
-			ProfileRepo: &repository.ProfileSQLRepository{
+			TasksRepo: &repository.TasksSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
-			RepliesRepo: &repository.RepliesSQLRepository{
+			NotificationsRepo: &repository.NotificationsSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
-			NotificationsRepo: &repository.NotificationsSQLRepository{
+			ExplorationRepo: &repository.ExplorationSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
-			TagsRepo: &repository.TagsSQLRepository{
+			IdempotencyRepo: &repository.IdempotencySQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
 		},
-		&controller.RepliesController{
+		&controller.NotificationsController{
+			NotificationsRepo: &repository.NotificationsSQLRepository{
+				SQLClient: db.GetSQLClient(),
+			},
 			RepliesRepo: &repository.RepliesSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
 			TasksRepo: &repository.TasksSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
-			NotificationsRepo: &repository.NotificationsSQLRepository{
+		},
+		&controller.TagsController{
+			TagsRepo: &repository.TagsSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
 		},
-		&controller.NotificationsController{
-			NotificationsRepo: &repository.NotificationsSQLRepository{
+		&controller.SubscriptionsController{
+			SubscriptionsRepo: &repository.SubscriptionsSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
-			RepliesRepo: &repository.RepliesSQLRepository{
+		},
+		&controller.AdminController{
+			ProfileRepo: &repository.ProfileSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
 			TasksRepo: &repository.TasksSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
-		},
-		&controller.TagsController{
 			TagsRepo: &repository.TagsSQLRepository{
 				SQLClient: db.GetSQLClient(),
 			},
+			SQLClient: db.GetSQLClient(),
 		},
 	}
 
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+// invalid environment configuration, reported to clients with CONFIG_ERROR instead of SQL_ERROR
+type ConfigError struct {
+	Name string
+	Err  error
+}
+
+func (err *ConfigError) Error() string {
+	return err.Name + ": " + err.Err.Error()
+}
+
+func (err *ConfigError) Unwrap() error {
+	return err.Err
+}
+
+func MakeConfigError(name string) error {
+	return &ConfigError{name, errors.New(INVALID_CONFIG)}
+}
+
+// picks the response code for errors of data access and configuration
+func MakeInternalErrorMessage(err error) ErrorMessage {
+	var configErr *ConfigError
+	if errors.As(err, &configErr) {
+		return MakeErrorMessage(CONFIG_ERROR)
+	}
+	return MakeErrorMessage(SQL_ERROR)
+}
+
+// fallback is returned when the variable is not set
+func GetEnvFloat(name string, fallback float64) (float64, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, &ConfigError{name, err}
+	}
+
+	return result, nil
+}
+
+func GetEnvInt(name string, fallback int) (int, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ConfigError{name, err}
+	}
+
+	return result, nil
+}
+
+func GetEnvBool(name string, fallback bool) (bool, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &ConfigError{name, err}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Scope, Sort and Query bind the cursor to the feed it was issued for,
+// At is the reference time of the first page, so time-dependent scores don't change between pages
+type Cursor struct {
+	ID      UID                `json:"id,omitempty"`
+	Score   float64            `json:"score,omitempty"`
+	End     bool               `json:"end,omitempty"`
+	Sources map[string]*Cursor `json:"sources,omitempty"`
+	Scope   string             `json:"scope,omitempty"`
+	Sort    string             `json:"sort,omitempty"`
+	Query   string             `json:"query,omitempty"`
+	At      *time.Time         `json:"at,omitempty"`
+}
+
+func (cursor *Cursor) String() string {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func CursorFromString(enc string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := Cursor{}
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
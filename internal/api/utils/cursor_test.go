@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2022, 5, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"empty", Cursor{}},
+		{"position", Cursor{ID: 42, Score: 0.75}},
+		{"end", Cursor{End: true}},
+		{"bound", Cursor{ID: 7, Scope: "TRENDING", Sort: "NEWEST", Query: "0123456789abcdef", At: &at}},
+		{"sources", Cursor{At: &at, Sources: map[string]*Cursor{
+			"RECOMMENDED": {ID: 1, Score: 0.5},
+			"FRESH":       {End: true},
+		}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := CursorFromString(test.cursor.String())
+			if err != nil {
+				t.Fatalf("CursorFromString() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(*result, test.cursor) {
+				t.Errorf("CursorFromString() = %+v, want %+v", *result, test.cursor)
+			}
+		})
+	}
+}
+
+func TestCursorFromInvalidString(t *testing.T) {
+	tests := []string{
+		"not base64!",
+		"bm90IGpzb24",
+	}
+
+	for _, test := range tests {
+		_, err := CursorFromString(test)
+		if err == nil {
+			t.Errorf("CursorFromString(%q) error = nil, want error", test)
+		}
+	}
+}
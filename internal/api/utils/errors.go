@@ -2,14 +2,36 @@ package utils
 
 //response errors readable codes
 const (
-	AUTHORIZATION_ERROR = "AUTHORIZATION_ERROR"
-	SQL_ERROR           = "SQL_ERROR"
-	DECODER_ERROR       = "DECODER_ERROR"
-	BAD_INPUT           = "BAD_INPUT"
+	AUTHORIZATION_ERROR    = "AUTHORIZATION_ERROR"
+	SQL_ERROR              = "SQL_ERROR"
+	CONFIG_ERROR           = "CONFIG_ERROR"
+	DECODER_ERROR          = "DECODER_ERROR"
+	BAD_INPUT              = "BAD_INPUT"
+	NOT_FOUND              = "NOT_FOUND"
+	PRECONDITION_REQUIRED  = "PRECONDITION_REQUIRED"
+	VERSION_CONFLICT       = "VERSION_CONFLICT"
+	INVALID_TRANSITION     = "INVALID_TRANSITION"
+	TASK_CLOSED            = "TASK_CLOSED"
+	IDEMPOTENCY_KEY_REUSED = "IDEMPOTENCY_KEY_REUSED"
+	IDEMPOTENCY_KEY_IN_USE = "IDEMPOTENCY_KEY_IN_USE"
+	TASK_HAS_DOER          = "TASK_HAS_DOER"
+	DUPLICATE_TASK         = "DUPLICATE_TASK"
 )
 
 //internal errors
 const (
-	INVALID_INPUT       = "got invalid data"
-	INSUFFICIENT_RIGHTS = "user has insufficient rights"
+	INVALID_INPUT           = "got invalid data"
+	INVALID_CONFIG          = "configuration value is out of range"
+	INSUFFICIENT_RIGHTS     = "user has insufficient rights"
+	OUTDATED_VERSION        = "resource version is outdated"
+	FORBIDDEN_TRANSITION    = "task state transition is not allowed"
+	TASK_NOT_OPEN           = "task is not open"
+	TASK_NOT_FOUND          = "task doesn't exist or was deleted"
+	REPLY_NOT_FOUND         = "reply doesn't exist"
+	TAG_NOT_FOUND           = "tag doesn't exist"
+	INVALID_DOER            = "doer has no visible reply to the task"
+	IDEMPOTENCY_MISMATCH    = "idempotency key was used for a different request"
+	IDEMPOTENCY_IN_PROGRESS = "request with this idempotency key is still in progress"
+	DOER_ALREADY_SELECTED   = "task already has a doer"
+	SIMILAR_TASK_EXISTS     = "similar open task already exists"
 )
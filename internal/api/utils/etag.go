@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func MakeVersionETag(version int32) string {
+	return `"` + strconv.FormatInt(int64(version), 10) + `"`
+}
+
+func trimETag(etag string) string {
+	return strings.Trim(strings.TrimPrefix(strings.TrimSpace(etag), "W/"), `"`)
+}
+
+// accepts both version and content etags, content ones are prefixed with the version
+func ParseVersionETag(etag string) (int32, error) {
+	value, _, _ := strings.Cut(trimETag(etag), "-")
+	version, err := strconv.ParseInt(value, 10, 32)
+	return int32(version), err
+}
+
+// strong etag for the response body, existing etag (usually the version) is kept as a prefix
+// so it still can be used with If-Match
+func MakeContentETag(prefix string, body []byte) string {
+	hash := sha256.Sum256(body)
+	value := hex.EncodeToString(hash[:16])
+
+	if prefix = trimETag(prefix); prefix != "" {
+		value = prefix + "-" + value
+	}
+
+	return `"` + value + `"`
+}
+
+// checks If-None-Match against the response etag, content etags cover counters
+// that change without a new version, so dates aren't used for revalidation
+func IsNotModified(r *http.Request, headers http.Header) bool {
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	etag := trimETag(headers.Get("ETag"))
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+
+	for _, value := range strings.Split(ifNoneMatch, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || trimETag(value) == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -8,10 +8,13 @@ import (
 
 type UID int64
 
-func (val UID) MarshalJSON() ([]byte, error) {
+func (val UID) String() string {
 	str := strconv.FormatInt(int64(val), 10)
-	enc := base64.StdEncoding.EncodeToString([]byte(str))
-	json, err := json.Marshal(enc)
+	return base64.StdEncoding.EncodeToString([]byte(str))
+}
+
+func (val UID) MarshalJSON() ([]byte, error) {
+	json, err := json.Marshal(val.String())
 	return json, err
 }
 
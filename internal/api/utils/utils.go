@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"database/sql"
+	"errors"
+	"io"
 	"net/http"
 )
 
@@ -12,14 +15,45 @@ func MakeErrorMessage(code string) ErrorMessage {
 	return ErrorMessage{code}
 }
 
+// if Stream is set, it writes the body instead of encoding Response
 type HandlerResponse struct {
 	Code     int
 	Response interface{}
 	Err      error
+	Headers  http.Header
+	Stream   func(w io.Writer) error
 }
 
 func MakeHandlerResponse(code int, response interface{}, err error) HandlerResponse {
-	return HandlerResponse{code, response, err}
+	return HandlerResponse{code, response, err, http.Header{}, nil}
+}
+
+// response for large bodies that are written directly to the client
+func MakeStreamResponse(contentType string, stream func(w io.Writer) error) HandlerResponse {
+	response := MakeHandlerResponse(http.StatusOK, nil, nil).WithHeader("Content-Type", contentType)
+	response.Stream = stream
+	return response
+}
+
+func (response HandlerResponse) WithHeader(key string, value string) HandlerResponse {
+	if response.Headers == nil {
+		response.Headers = http.Header{}
+	}
+	response.Headers.Set(key, value)
+	return response
+}
+
+// response for POST endpoints that create a resource, location is the path to the created resource
+func MakeCreatedResponse(location string, resource interface{}) HandlerResponse {
+	return MakeHandlerResponse(http.StatusCreated, resource, nil).WithHeader("Location", location)
+}
+
+// response for failed data access, missing and deleted resources are reported as not found
+func MakeInternalErrorResponse(err error) HandlerResponse {
+	if errors.Is(err, sql.ErrNoRows) {
+		return MakeHandlerResponse(http.StatusNotFound, MakeErrorMessage(NOT_FOUND), err)
+	}
+	return MakeHandlerResponse(http.StatusInternalServerError, MakeInternalErrorMessage(err), err)
 }
 
 type BaseHandler func(*http.Request) HandlerResponse
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestMakeInternalErrorResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{"not found", sql.ErrNoRows, http.StatusNotFound, NOT_FOUND},
+		{"wrapped not found", fmt.Errorf("task: %w", sql.ErrNoRows), http.StatusNotFound, NOT_FOUND},
+		{"config error", MakeConfigError("NAME"), http.StatusInternalServerError, CONFIG_ERROR},
+		{"sql error", errors.New("connection refused"), http.StatusInternalServerError, SQL_ERROR},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := MakeInternalErrorResponse(test.err)
+			if response.Code != test.status {
+				t.Errorf("MakeInternalErrorResponse(%v) code = %d, want %d", test.err, response.Code, test.status)
+			}
+
+			if message := response.Response.(ErrorMessage); message.Code != test.message {
+				t.Errorf("MakeInternalErrorResponse(%v) message = %s, want %s", test.err, message.Code, test.message)
+			}
+
+			if response.Err != test.err {
+				t.Errorf("MakeInternalErrorResponse(%v) keeps error %v", test.err, response.Err)
+			}
+		})
+	}
+}
+
+func TestIsNotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		value       string
+		etag        string
+		notModified bool
+	}{
+		{"matching etag", "If-None-Match", `"3-abc"`, `"3-abc"`, true},
+		{"weak etag", "If-None-Match", `W/"3-abc"`, `"3-abc"`, true},
+		{"one of etags", "If-None-Match", `"2-def", "3-abc"`, `"3-abc"`, true},
+		{"any etag", "If-None-Match", "*", `"3-abc"`, true},
+		{"changed content", "If-None-Match", `"3-abc"`, `"3-def"`, false},
+		{"no etag", "If-None-Match", `"3-abc"`, "", false},
+		{"dates are ignored", "If-Modified-Since", "Mon, 02 Jan 2006 15:04:05 GMT", `"3-abc"`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", "/", nil)
+			r.Header.Set(test.header, test.value)
+
+			headers := http.Header{}
+			if test.etag != "" {
+				headers.Set("ETag", test.etag)
+			}
+
+			if result := IsNotModified(r, headers); result != test.notModified {
+				t.Errorf("IsNotModified() = %v, want %v", result, test.notModified)
+			}
+		})
+	}
+}
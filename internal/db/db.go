@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 
 	_ "github.com/lib/pq"
@@ -38,22 +39,86 @@ func (reader *SQLResponseReader) Close() {
 	}
 }
 
+type sqlExecutor interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// tx is set only for clients created by WithTx,
+// such client runs all queries inside of that transaction
 type SQLClient struct {
 	db *sql.DB
+	tx *sql.Tx
 }
 
 var client *SQLClient
 
+func (client *SQLClient) executor() sqlExecutor {
+	if client.tx != nil {
+		return client.tx
+	}
+	return client.db
+}
+
 func (client *SQLClient) Query(query string, args ...interface{}) (*SQLResponseReader, error) {
-	response, err := client.db.Query(query, args...)
+	response, err := client.executor().Query(query, args...)
 	return &SQLResponseReader{response}, err
 }
 
 func (client *SQLClient) Exec(query string, args ...interface{}) error {
-	_, err := client.db.Exec(query, args...)
+	_, err := client.executor().Exec(query, args...)
 	return err
 }
 
+// runs fn inside of a transaction, commits it if fn succeeds and rolls back otherwise,
+// nested calls join the outer transaction
+func (client *SQLClient) WithTx(ctx context.Context, fn func(tx *SQLClient) error) (err error) {
+	if client.tx != nil {
+		return fn(client)
+	}
+
+	tx, err := client.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	return fn(&SQLClient{db: client.db, tx: tx})
+}
+
+// runs fn only if the session advisory lock for key is acquired, the lock is held on a dedicated connection
+// and released when fn returns, so only one instance runs fn at a time
+func (client *SQLClient) WithAdvisoryLock(ctx context.Context, key string, fn func() error) (bool, error) {
+	conn, err := client.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	locked := false
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", key).Scan(&locked)
+	if err != nil || !locked {
+		return false, err
+	}
+
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", key)
+
+	return true, fn()
+}
+
 func GetSQLClient() *SQLClient {
 	return client
 }
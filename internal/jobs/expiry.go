@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+type ExpiryJobs struct {
+	TasksRepo repository.TasksRepository
+}
+
+func (jobs *ExpiryJobs) GetJobs() []Job {
+	return []Job{
+		{
+			Name:     "Expire Tasks",
+			Interval: time.Hour,
+			Run:      jobs.ExpireTasks,
+		},
+	}
+}
+
+// expiry period below a day would expire every open task at once
+func (jobs *ExpiryJobs) ExpireTasks() error {
+	expiryDays, err := utils.GetEnvInt("TASK_EXPIRY_DAYS", 30)
+	if err != nil {
+		return err
+	}
+
+	if expiryDays < 1 {
+		return utils.MakeConfigError("TASK_EXPIRY_DAYS")
+	}
+
+	return jobs.TasksRepo.ExpireTasks(expiryDays)
+}
@@ -0,0 +1,21 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/controller"
+)
+
+type PublishJobs struct {
+	TasksController *controller.TasksController
+}
+
+func (jobs *PublishJobs) GetJobs() []Job {
+	return []Job{
+		{
+			Name:     "Publish Scheduled Tasks",
+			Interval: time.Minute,
+			Run:      jobs.TasksController.PublishScheduledTasks,
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/middleware"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/utils"
+)
+
+type PurgeJobs struct {
+	TasksRepo       repository.TasksRepository
+	IdempotencyRepo repository.IdempotencyRepository
+}
+
+func (jobs *PurgeJobs) GetJobs() []Job {
+	return []Job{
+		{
+			Name:     "Purge Deleted Tasks",
+			Interval: time.Hour,
+			Run:      jobs.PurgeDeletedTasks,
+		},
+		{
+			Name:     "Purge Idempotency Keys",
+			Interval: time.Hour,
+			Run:      jobs.PurgeIdempotencyKeys,
+		},
+	}
+}
+
+// retention below an hour would purge tasks right after they are deleted
+func (jobs *PurgeJobs) PurgeDeletedTasks() error {
+	retention, err := utils.GetEnvInt("TASK_PURGE_RETENTION_HOURS", 720)
+	if err != nil {
+		return err
+	}
+
+	if retention < 1 {
+		return utils.MakeConfigError("TASK_PURGE_RETENTION_HOURS")
+	}
+
+	return jobs.TasksRepo.PurgeDeletedTasks(retention)
+}
+
+func (jobs *PurgeJobs) PurgeIdempotencyKeys() error {
+	retention, err := middleware.GetIdempotencyRetentionHours()
+	if err != nil {
+		return err
+	}
+
+	return jobs.IdempotencyRepo.PurgeExpiredKeys(retention)
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/st-matskevich/item-based-recommendations/internal/api/controller"
+	"github.com/st-matskevich/item-based-recommendations/internal/api/repository"
+	"github.com/st-matskevich/item-based-recommendations/internal/db"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+type JobsProvider interface {
+	GetJobs() []Job
+}
+
+// every instance runs the scheduler, advisory lock makes sure the job runs only on one of them at a time
+func runJob(job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		locked, err := db.GetSQLClient().WithAdvisoryLock(context.Background(), "job:"+job.Name, job.Run)
+
+		if locked {
+			log.Printf("%-8s %-32s %-10s", "JOB", job.Name, time.Since(start))
+		}
+		if err != nil {
+			log.Printf("%s error: %v", job.Name, err)
+		}
+
+		<-ticker.C
+	}
+}
+
+func StartScheduler() {
+	providers := []JobsProvider{
+		&PurgeJobs{
+			TasksRepo: &repository.TasksSQLRepository{
+				SQLClient: db.GetSQLClient(),
+			},
+			IdempotencyRepo: &repository.IdempotencySQLRepository{
+				SQLClient: db.GetSQLClient(),
+			},
+		},
+		&PublishJobs{
+			TasksController: controller.NewTasksController(db.GetSQLClient()),
+		},
+		&ExpiryJobs{
+			TasksRepo: &repository.TasksSQLRepository{
+				SQLClient: db.GetSQLClient(),
+			},
+		},
+	}
+
+	for _, provider := range providers {
+		for _, job := range provider.GetJobs() {
+			go runJob(job)
+		}
+	}
+}
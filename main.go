@@ -9,6 +9,7 @@ import (
 	"github.com/st-matskevich/item-based-recommendations/internal/api"
 	"github.com/st-matskevich/item-based-recommendations/internal/db"
 	"github.com/st-matskevich/item-based-recommendations/internal/firebase"
+	"github.com/st-matskevich/item-based-recommendations/internal/jobs"
 )
 
 func startRouter() {
@@ -34,5 +35,6 @@ func init() {
 }
 
 func main() {
+	jobs.StartScheduler()
 	startRouter()
 }
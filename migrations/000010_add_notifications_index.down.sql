@@ -0,0 +1 @@
+DROP INDEX notifications_user_type_index;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX notifications_user_type_index ON notifications(user_id, type, created_at);
\ No newline at end of file
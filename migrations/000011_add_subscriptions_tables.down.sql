@@ -0,0 +1,2 @@
+DROP TABLE saved_searches;
+DROP TABLE tag_subscriptions;
\ No newline at end of file
@@ -0,0 +1,28 @@
+CREATE TABLE tag_subscriptions(
+    user_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT tag_subscriptions_user_tag
+        UNIQUE (user_id, tag_id),
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id) 
+            REFERENCES users(user_id)
+                ON DELETE CASCADE,
+    CONSTRAINT fk_tag
+        FOREIGN KEY(tag_id) 
+            REFERENCES tags(tag_id)
+                ON DELETE CASCADE);
+
+CREATE TABLE saved_searches(
+    search_id BIGINT PRIMARY KEY NOT NULL DEFAULT id_generator(),
+    user_id BIGINT NOT NULL,
+    scope VARCHAR(32) NOT NULL DEFAULT 'NOT_ASSIGNED',
+    query VARCHAR(128) NOT NULL DEFAULT '',
+    tags BIGINT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT saved_searches_scope
+        CHECK (scope IN ('NOT_ASSIGNED', 'TRENDING')),
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id) 
+            REFERENCES users(user_id)
+                ON DELETE CASCADE);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE exploration_slots;
\ No newline at end of file
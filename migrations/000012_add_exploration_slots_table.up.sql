@@ -0,0 +1,20 @@
+CREATE TABLE exploration_slots(
+    user_id BIGINT NOT NULL,
+    task_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    rewarded BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT exploration_slots_user_task
+        UNIQUE (user_id, task_id),
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id) 
+            REFERENCES users(user_id)
+                ON DELETE CASCADE,
+    CONSTRAINT fk_task
+        FOREIGN KEY(task_id) 
+            REFERENCES tasks(task_id)
+                ON DELETE CASCADE,
+    CONSTRAINT fk_tag
+        FOREIGN KEY(tag_id) 
+            REFERENCES tags(tag_id)
+                ON DELETE CASCADE);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE users ADD is_admin BOOLEAN NOT NULL DEFAULT false;
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP FUNCTION task_trending_score;
+DROP TRIGGER replies_engagement_trigger ON replies;
+DROP TRIGGER likes_engagement_trigger ON likes;
+DROP FUNCTION track_reply_engagement;
+DROP FUNCTION track_like_engagement;
+DROP FUNCTION track_task_engagement;
+DROP TABLE task_engagement;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX tasks_search_vector_index;
+ALTER TABLE tasks DROP COLUMN search_vector;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') || setweight(to_tsvector('simple', description), 'B')
+) STORED;
+
+CREATE INDEX tasks_search_vector_index ON tasks USING GIN(search_vector);
\ No newline at end of file
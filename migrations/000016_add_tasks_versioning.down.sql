@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN version;
+ALTER TABLE tasks DROP COLUMN updated_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD updated_at TIMESTAMP NOT NULL DEFAULT now();
+ALTER TABLE tasks ADD version INTEGER NOT NULL DEFAULT 1;
+UPDATE tasks SET updated_at = created_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE task_state_history;
+DROP INDEX tasks_state;
+ALTER TABLE tasks DROP COLUMN state;
\ No newline at end of file
@@ -0,0 +1,18 @@
+ALTER TABLE tasks ADD state VARCHAR(16) NOT NULL DEFAULT 'OPEN';
+UPDATE tasks SET state = 'ASSIGNED' WHERE doer_id IS NOT NULL;
+CREATE INDEX tasks_state ON tasks(state);
+CREATE TABLE task_state_history(
+    history_id BIGINT PRIMARY KEY NOT NULL DEFAULT id_generator(),
+    task_id BIGINT NOT NULL,
+    user_id BIGINT,
+    from_state VARCHAR(16) NOT NULL,
+    to_state VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fk_task
+        FOREIGN KEY(task_id) 
+            REFERENCES tasks(task_id)
+                ON DELETE CASCADE,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id) 
+            REFERENCES users(user_id)
+                ON DELETE SET NULL);
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE idempotency_keys(
+    user_id BIGINT NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    response_code INTEGER,
+    response_body TEXT,
+    response_headers TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT idempotency_keys_user_key
+        UNIQUE (user_id, key),
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id) 
+            REFERENCES users(user_id)
+                ON DELETE CASCADE);
+CREATE INDEX idempotency_keys_created_at ON idempotency_keys(created_at);
\ No newline at end of file
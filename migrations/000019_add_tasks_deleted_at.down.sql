@@ -0,0 +1,2 @@
+DROP INDEX tasks_deleted_at;
+ALTER TABLE tasks DROP COLUMN deleted_at;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD deleted_at TIMESTAMP;
+CREATE INDEX tasks_deleted_at ON tasks(deleted_at) WHERE deleted_at IS NOT NULL;
\ No newline at end of file
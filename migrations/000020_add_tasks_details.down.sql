@@ -0,0 +1,11 @@
+DROP FUNCTION haversine_distance;
+DROP INDEX tasks_location;
+DROP INDEX tasks_deadline;
+ALTER TABLE tasks DROP CONSTRAINT tasks_budget_range;
+ALTER TABLE tasks DROP COLUMN place;
+ALTER TABLE tasks DROP COLUMN longitude;
+ALTER TABLE tasks DROP COLUMN latitude;
+ALTER TABLE tasks DROP COLUMN deadline;
+ALTER TABLE tasks DROP COLUMN budget_currency;
+ALTER TABLE tasks DROP COLUMN budget_max;
+ALTER TABLE tasks DROP COLUMN budget_min;
\ No newline at end of file
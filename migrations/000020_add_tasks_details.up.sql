@@ -0,0 +1,18 @@
+ALTER TABLE tasks ADD budget_min INTEGER;
+ALTER TABLE tasks ADD budget_max INTEGER;
+ALTER TABLE tasks ADD budget_currency CHAR(3);
+ALTER TABLE tasks ADD deadline TIMESTAMP;
+ALTER TABLE tasks ADD latitude DOUBLE PRECISION;
+ALTER TABLE tasks ADD longitude DOUBLE PRECISION;
+ALTER TABLE tasks ADD place VARCHAR(128);
+ALTER TABLE tasks ADD CONSTRAINT tasks_budget_range
+    CHECK (budget_min >= 0 AND budget_min <= budget_max);
+CREATE INDEX tasks_deadline ON tasks(deadline) WHERE deadline IS NOT NULL;
+CREATE INDEX tasks_location ON tasks(latitude, longitude) WHERE latitude IS NOT NULL;
+-- great-circle distance in kilometers between two points given in degrees
+CREATE OR REPLACE FUNCTION haversine_distance(lat1 DOUBLE PRECISION, lon1 DOUBLE PRECISION, lat2 DOUBLE PRECISION, lon2 DOUBLE PRECISION) RETURNS DOUBLE PRECISION AS $$
+    SELECT 2 * 6371 * ASIN(LEAST(1, SQRT(
+        POWER(SIN(RADIANS(lat2 - lat1) / 2), 2) +
+        COS(RADIANS(lat1)) * COS(RADIANS(lat2)) * POWER(SIN(RADIANS(lon2 - lon1) / 2), 2)
+    )))
+$$ LANGUAGE SQL IMMUTABLE;
\ No newline at end of file
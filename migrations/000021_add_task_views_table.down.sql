@@ -0,0 +1 @@
+DROP TABLE task_views;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE task_views(
+    task_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    day DATE NOT NULL DEFAULT CURRENT_DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT task_views_task_user_day
+        UNIQUE (task_id, user_id, day),
+    CONSTRAINT fk_task
+        FOREIGN KEY(task_id) 
+            REFERENCES tasks(task_id)
+                ON DELETE CASCADE,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id) 
+            REFERENCES users(user_id)
+                ON DELETE CASCADE);
+
+CREATE INDEX task_views_task_day ON task_views(task_id, day);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE tasks DROP COLUMN likes_count;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD likes_count INTEGER NOT NULL DEFAULT 0;
+UPDATE tasks SET likes_count = (SELECT COUNT(*) FROM likes WHERE likes.task_id = tasks.task_id AND likes.active);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX tasks_publish_at;
+ALTER TABLE tasks DROP COLUMN publish_at;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD publish_at TIMESTAMP;
+CREATE INDEX tasks_publish_at ON tasks(publish_at) WHERE state = 'DRAFT';
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX tasks_opened_at;
+ALTER TABLE tasks DROP COLUMN opened_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD opened_at TIMESTAMP NOT NULL DEFAULT now();
+UPDATE tasks SET opened_at = created_at;
+CREATE INDEX tasks_opened_at ON tasks(opened_at) WHERE state = 'OPEN';
\ No newline at end of file
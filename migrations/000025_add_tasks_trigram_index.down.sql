@@ -0,0 +1,2 @@
+DROP INDEX tasks_text_trgm_index;
+DROP EXTENSION IF EXISTS pg_trgm;
\ No newline at end of file
@@ -0,0 +1,2 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX tasks_text_trgm_index ON tasks USING GIN((name || ' ' || description) gin_trgm_ops) WHERE state = 'OPEN' AND deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,42 @@
+CREATE OR REPLACE FUNCTION task_trending_score(task BIGINT, created TIMESTAMP, window_hours DOUBLE PRECISION, half_life_hours DOUBLE PRECISION) RETURNS DOUBLE PRECISION AS $$
+    SELECT COALESCE(SUM((task_engagement.likes + 2 * task_engagement.replies) * POWER(0.5, EXTRACT(EPOCH FROM now() - task_engagement.bucket) / 3600 / half_life_hours)), 0)
+        / SQRT(EXTRACT(EPOCH FROM now() - created) / 3600 + 2)
+    FROM task_engagement
+    WHERE task_engagement.task_id = task
+    AND task_engagement.bucket > now() - window_hours * INTERVAL '1 hour'
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION track_task_engagement(task BIGINT, likes_delta INTEGER, replies_delta INTEGER) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO task_engagement(task_id, bucket, likes, replies)
+    VALUES (task, DATE_TRUNC('hour', now()), likes_delta, replies_delta)
+    ON CONFLICT ON CONSTRAINT task_engagement_task_bucket DO UPDATE
+    SET likes = task_engagement.likes + likes_delta, replies = task_engagement.replies + replies_delta;
+END;
+$$ LANGUAGE PLPGSQL;
+
+CREATE OR REPLACE FUNCTION track_like_engagement() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.active THEN
+        PERFORM track_task_engagement(NEW.task_id, 1, 0);
+    ELSIF TG_OP = 'UPDATE' AND NEW.active AND NOT OLD.active THEN
+        PERFORM track_task_engagement(NEW.task_id, 1, 0);
+    ELSIF TG_OP = 'UPDATE' AND OLD.active AND NOT NEW.active THEN
+        PERFORM track_task_engagement(NEW.task_id, -1, 0);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE PLPGSQL;
+
+CREATE OR REPLACE FUNCTION track_reply_engagement() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NOT NEW.hidden THEN
+        PERFORM track_task_engagement(NEW.task_id, 0, 1);
+    ELSIF TG_OP = 'UPDATE' AND NEW.hidden AND NOT OLD.hidden THEN
+        PERFORM track_task_engagement(NEW.task_id, 0, -1);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE PLPGSQL;
+
+DROP FUNCTION track_task_engagement(BIGINT, TIMESTAMP, INTEGER, INTEGER);
\ No newline at end of file
@@ -0,0 +1,56 @@
+CREATE OR REPLACE FUNCTION track_task_engagement(task BIGINT, event_time TIMESTAMP, likes_delta INTEGER, replies_delta INTEGER) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO task_engagement(task_id, bucket, likes, replies)
+    VALUES (task, DATE_TRUNC('hour', event_time), likes_delta, replies_delta)
+    ON CONFLICT ON CONSTRAINT task_engagement_task_bucket DO UPDATE
+    SET likes = task_engagement.likes + likes_delta, replies = task_engagement.replies + replies_delta;
+END;
+$$ LANGUAGE PLPGSQL;
+
+-- events are reverted in the bucket they were counted in,
+-- likes.created_at is moved to the time of the last activation
+CREATE OR REPLACE FUNCTION track_like_engagement() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.active THEN
+        PERFORM track_task_engagement(NEW.task_id, NEW.created_at, 1, 0);
+    ELSIF TG_OP = 'UPDATE' AND NEW.active AND NOT OLD.active THEN
+        PERFORM track_task_engagement(NEW.task_id, NEW.created_at, 1, 0);
+    ELSIF TG_OP = 'UPDATE' AND OLD.active AND NOT NEW.active THEN
+        PERFORM track_task_engagement(NEW.task_id, OLD.created_at, -1, 0);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE PLPGSQL;
+
+CREATE OR REPLACE FUNCTION track_reply_engagement() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NOT NEW.hidden THEN
+        PERFORM track_task_engagement(NEW.task_id, NEW.created_at, 0, 1);
+    ELSIF TG_OP = 'UPDATE' AND NEW.hidden AND NOT OLD.hidden THEN
+        PERFORM track_task_engagement(NEW.task_id, NEW.created_at, 0, -1);
+    ELSIF TG_OP = 'UPDATE' AND OLD.hidden AND NOT NEW.hidden THEN
+        PERFORM track_task_engagement(NEW.task_id, NEW.created_at, 0, 1);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE PLPGSQL;
+
+DROP FUNCTION track_task_engagement(BIGINT, INTEGER, INTEGER);
+
+-- buckets are rebuilt, since previous reverts could be counted in wrong ones
+DELETE FROM task_engagement;
+INSERT INTO task_engagement(task_id, bucket, likes, replies)
+SELECT task_id, bucket, SUM(likes), SUM(replies) FROM (
+    SELECT task_id, DATE_TRUNC('hour', created_at) AS bucket, 1 AS likes, 0 AS replies FROM likes WHERE active = true
+    UNION ALL
+    SELECT task_id, DATE_TRUNC('hour', created_at) AS bucket, 0 AS likes, 1 AS replies FROM replies WHERE hidden = false
+) AS events
+GROUP BY task_id, bucket;
+
+CREATE OR REPLACE FUNCTION task_trending_score(task BIGINT, created TIMESTAMP, window_hours DOUBLE PRECISION, half_life_hours DOUBLE PRECISION) RETURNS DOUBLE PRECISION AS $$
+    SELECT GREATEST(COALESCE(SUM((task_engagement.likes + 2 * task_engagement.replies) * POWER(0.5, EXTRACT(EPOCH FROM now() - task_engagement.bucket) / 3600 / half_life_hours)), 0), 0)
+        / SQRT(EXTRACT(EPOCH FROM now() - created) / 3600 + 2)
+    FROM task_engagement
+    WHERE task_engagement.task_id = task
+    AND task_engagement.bucket > now() - window_hours * INTERVAL '1 hour'
+$$ LANGUAGE SQL STABLE;
\ No newline at end of file
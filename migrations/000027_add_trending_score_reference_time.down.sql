@@ -0,0 +1 @@
+DROP FUNCTION task_trending_score(BIGINT, TIMESTAMP, DOUBLE PRECISION, DOUBLE PRECISION, TIMESTAMP);
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- same as the 4 arguments version, but computed at the reference time instead of now(),
+-- so the score of a task doesn't change between feed pages
+CREATE OR REPLACE FUNCTION task_trending_score(task BIGINT, created TIMESTAMP, window_hours DOUBLE PRECISION, half_life_hours DOUBLE PRECISION, reference TIMESTAMP) RETURNS DOUBLE PRECISION AS $$
+    SELECT GREATEST(COALESCE(SUM((task_engagement.likes + 2 * task_engagement.replies) * POWER(0.5, EXTRACT(EPOCH FROM reference - task_engagement.bucket) / 3600 / half_life_hours)), 0), 0)
+        / SQRT(GREATEST(EXTRACT(EPOCH FROM reference - created) / 3600, 0) + 2)
+    FROM task_engagement
+    WHERE task_engagement.task_id = task
+    AND task_engagement.bucket > reference - window_hours * INTERVAL '1 hour'
+    AND task_engagement.bucket <= reference
+$$ LANGUAGE SQL STABLE;
\ No newline at end of file
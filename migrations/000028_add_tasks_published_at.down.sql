@@ -0,0 +1,2 @@
+DROP INDEX tasks_published_at;
+ALTER TABLE tasks DROP COLUMN published_at;
\ No newline at end of file
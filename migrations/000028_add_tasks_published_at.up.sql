@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD published_at TIMESTAMP;
+UPDATE tasks SET published_at = created_at WHERE state <> 'DRAFT';
+CREATE INDEX tasks_published_at ON tasks(published_at) WHERE state = 'OPEN';
\ No newline at end of file